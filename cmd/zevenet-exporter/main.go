@@ -0,0 +1,56 @@
+// Command zevenet-exporter provides Prometheus metrics on a Zevenet loadbalancer.
+//
+// Usage:
+//
+//	ZAPI_KEY=... zevenet-exporter -host myloadbalancer:444 -listen :9713
+//
+// The metrics are available at http://localhost:9713/metrics.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"github.com/konsorten/zevenet-lb-go/exporter"
+)
+
+func main() {
+	host := flag.String("host", os.Getenv("ZAPI_HOSTNAME"), "Hostname and port of the loadbalancer, e.g. myloadbalancer:444")
+	listen := flag.String("listen", ":9713", "Address to serve the metrics on")
+	path := flag.String("path", "/metrics", "Path to serve the metrics on")
+	fingerprint := flag.String("fingerprint", os.Getenv("ZAPI_FINGERPRINT"), "SHA-256 fingerprint of the loadbalancer's certificate")
+	insecure := flag.Bool("insecure", false, "Disable the verification of the loadbalancer's certificate")
+	timeout := flag.Duration("timeout", 30*time.Second, "Timeout of a single ZAPI call")
+	skipCerts := flag.Bool("skip-certificates", false, "Disable the certificate expiry metrics")
+
+	flag.Parse()
+
+	zapiKey := os.Getenv("ZAPI_KEY")
+
+	if *host == "" || zapiKey == "" {
+		log.Fatal("The loadbalancer has to be set using -host and the ZAPI key using the environment variable ZAPI_KEY")
+	}
+
+	session, err := zevenetlb.Connect(*host, zapiKey, &zevenetlb.ConfigOptions{
+		APICallTimeout:        *timeout,
+		TLSPinnedFingerprint:  *fingerprint,
+		TLSInsecureSkipVerify: *insecure,
+		RetryPolicy:           zevenetlb.DefaultRetryPolicy(),
+	})
+
+	if err != nil {
+		log.Fatalf("Failed to connect to %v: %v", *host, err)
+	}
+
+	exp := exporter.New(session)
+	exp.SkipCertificates = *skipCerts
+
+	http.Handle(*path, exp)
+
+	log.Printf("Serving metrics of %v on %v%v", session, *listen, *path)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,651 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+//
+// Farms
+//
+
+func farmsList(c *cli, fs *flag.FlagSet) error {
+	_, err := c.parse(fs, 0)
+
+	if err != nil {
+		return err
+	}
+
+	farms, err := c.session.GetAllFarms()
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(farms, func() *table {
+		t := &table{header: []string{"NAME", "PROFILE", "STATUS", "VIRTUAL IP", "PORT"}}
+
+		for _, f := range farms {
+			t.add(f.FarmName, f.Profile, f.Status, f.VirtualIP, f.VirtualPort)
+		}
+
+		return t
+	})
+}
+
+func farmsGet(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	farm, err := c.getFarm(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(farm, fieldsTable(farm))
+}
+
+func farmsCreate(c *cli, fs *flag.FlagSet) error {
+	https := fs.Bool("https", false, "Create an HTTPS farm")
+	cert := fs.String("certificate", "zencert.pem", "Certificate of the HTTPS farm")
+
+	args, err := c.parse(fs, 3)
+
+	if err != nil {
+		return err
+	}
+
+	port, err := parseNumber("virtual port", args[2])
+
+	if err != nil {
+		return err
+	}
+
+	var farm *zevenetlb.FarmDetails
+
+	if *https {
+		farm, err = c.session.CreateFarmAsHTTPS(args[0], args[1], port, *cert)
+	} else {
+		farm, err = c.session.CreateFarmAsHTTP(args[0], args[1], port)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(farm, fieldsTable(farm))
+}
+
+func farmsDelete(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	deleted, err := c.session.DeleteFarm(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Farm not found: %v", args[0])
+	}
+
+	return c.done("Farm %v deleted", args[0])
+}
+
+func farmsStart(c *cli, fs *flag.FlagSet) error {
+	return c.farmAction(fs, "started", c.session.StartFarm)
+}
+
+func farmsStop(c *cli, fs *flag.FlagSet) error {
+	return c.farmAction(fs, "stopped", c.session.StopFarm)
+}
+
+func farmsRestart(c *cli, fs *flag.FlagSet) error {
+	return c.farmAction(fs, "restarted", c.session.RestartFarm)
+}
+
+func (c *cli) farmAction(fs *flag.FlagSet, verb string, fn func(farmName string) error) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	err = fn(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	return c.done("Farm %v %v", args[0], verb)
+}
+
+// getFarm retrieves a farm, failing if not found.
+func (c *cli) getFarm(farmName string) (*zevenetlb.FarmDetails, error) {
+	farm, err := c.session.GetFarm(farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if farm == nil {
+		return nil, fmt.Errorf("Farm not found: %v", farmName)
+	}
+
+	return farm, nil
+}
+
+//
+// Services
+//
+
+func servicesList(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	farm, err := c.getFarm(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(farm.Services, func() *table {
+		t := &table{header: []string{"NAME", "HOST", "URL", "BACKENDS", "FARMGUARDIAN"}}
+
+		for _, s := range farm.Services {
+			t.add(s.ServiceName, s.HostPattern, s.URLPattern, len(s.Backends), s.FarmGuardianEnabled)
+		}
+
+		return t
+	})
+}
+
+func servicesGet(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	service, err := c.getService(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(service, fieldsTable(service))
+}
+
+func servicesCreate(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	service, err := c.session.CreateService(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(service, fieldsTable(service))
+}
+
+func servicesDelete(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	deleted, err := c.session.DeleteService(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Service not found: %v/%v", args[0], args[1])
+	}
+
+	return c.done("Service %v/%v deleted", args[0], args[1])
+}
+
+// getService retrieves a service, failing if not found.
+func (c *cli) getService(farmName string, serviceName string) (*zevenetlb.ServiceDetails, error) {
+	farm, err := c.getFarm(farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range farm.Services {
+		if farm.Services[i].ServiceName == serviceName {
+			return &farm.Services[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Service not found: %v/%v", farmName, serviceName)
+}
+
+//
+// Backends
+//
+
+func backendsList(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	service, err := c.getService(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(service.Backends, func() *table {
+		t := &table{header: []string{"ID", "IP", "PORT", "STATUS", "WEIGHT", "TIMEOUT"}}
+
+		for _, b := range service.Backends {
+			t.add(b.ID, b.IPAddress, b.Port, b.Status, optionalNumber(b.Weight), optionalNumber(b.TimeoutSeconds))
+		}
+
+		return t
+	})
+}
+
+func backendsAdd(c *cli, fs *flag.FlagSet) error {
+	weight := fs.Int("weight", 0, "Weight of the backend")
+	timeout := fs.Int("timeout", 0, "Timeout of the backend in seconds")
+
+	args, err := c.parse(fs, 4)
+
+	if err != nil {
+		return err
+	}
+
+	port, err := parseNumber("port", args[3])
+
+	if err != nil {
+		return err
+	}
+
+	backend, err := c.session.CreateBackend(args[0], args[1], args[2], port)
+
+	if err != nil {
+		return err
+	}
+
+	// update the optional settings
+	if *weight > 0 || *timeout > 0 {
+		if *weight > 0 {
+			backend.Weight = weight
+		}
+
+		if *timeout > 0 {
+			backend.TimeoutSeconds = timeout
+		}
+
+		err = c.session.UpdateBackend(backend)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.print(backend, fieldsTable(backend))
+}
+
+func backendsRemove(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 3)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseNumber("backend ID", args[2])
+
+	if err != nil {
+		return err
+	}
+
+	deleted, err := c.session.DeleteBackend(args[0], args[1], id)
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Backend not found: %v/%v/%v", args[0], args[1], id)
+	}
+
+	return c.done("Backend %v/%v/%v removed", args[0], args[1], id)
+}
+
+func backendsMaintenance(c *cli, fs *flag.FlagSet) error {
+	cut := fs.Bool("cut", false, "Cut the existing connections when enabling the maintenance")
+
+	args, err := c.parse(fs, 4)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseNumber("backend ID", args[2])
+
+	if err != nil {
+		return err
+	}
+
+	var enable bool
+
+	switch args[3] {
+	case "on":
+		enable = true
+	case "off":
+		enable = false
+	default:
+		return fmt.Errorf("Expected on or off, got %v", args[3])
+	}
+
+	service, err := c.getService(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	for i := range service.Backends {
+		backend := &service.Backends[i]
+
+		if backend.ID != id {
+			continue
+		}
+
+		err = c.session.SetBackendMaintenance(backend, enable, *cut)
+
+		if err != nil {
+			return err
+		}
+
+		if enable {
+			return c.done("Backend %v/%v/%v in maintenance", args[0], args[1], id)
+		}
+
+		return c.done("Backend %v/%v/%v back from maintenance", args[0], args[1], id)
+	}
+
+	return fmt.Errorf("Backend not found: %v/%v/%v", args[0], args[1], id)
+}
+
+//
+// Interfaces
+//
+
+func interfacesList(c *cli, fs *flag.FlagSet) error {
+	_, err := c.parse(fs, 0)
+
+	if err != nil {
+		return err
+	}
+
+	nics, err := c.session.GetAllNetworkInterfaces()
+
+	if err != nil {
+		return err
+	}
+
+	virtuals, err := c.session.GetAllVirtualInterfaces()
+
+	if err != nil {
+		return err
+	}
+
+	value := map[string]interface{}{
+		"nics":    nics,
+		"virtual": virtuals,
+	}
+
+	return c.print(value, func() *table {
+		t := &table{header: []string{"NAME", "TYPE", "IP", "NETMASK", "MAC", "STATUS"}}
+
+		for _, n := range nics {
+			t.add(n.Name, "nic", n.IP, n.Netmask, n.MAC, n.Status)
+		}
+
+		for _, v := range virtuals {
+			t.add(v.Name, "virtual", v.IP, v.Netmask, v.MAC, v.Status)
+		}
+
+		return t
+	})
+}
+
+func interfacesGet(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	vint, err := c.session.GetVirtualInterface(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if vint == nil {
+		return fmt.Errorf("Virtual interface not found: %v", args[0])
+	}
+
+	return c.print(vint, fieldsTable(vint))
+}
+
+func interfacesCreate(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	vint, err := c.session.CreateVirtualInterface(args[0], args[1])
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(vint, fieldsTable(vint))
+}
+
+func interfacesDelete(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	deleted, err := c.session.DeleteVirtualInterface(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Virtual interface not found: %v", args[0])
+	}
+
+	return c.done("Virtual interface %v deleted", args[0])
+}
+
+//
+// Certificates
+//
+
+func certificatesList(c *cli, fs *flag.FlagSet) error {
+	_, err := c.parse(fs, 0)
+
+	if err != nil {
+		return err
+	}
+
+	certs, err := c.session.GetAllCertificates()
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(certs, func() *table {
+		t := &table{header: []string{"FILE", "TYPE", "COMMON NAME", "ISSUER", "EXPIRATION"}}
+
+		for _, cert := range certs {
+			t.add(cert.Filename, cert.Type, cert.CommonName, cert.Issuer, cert.ExpirationDate)
+		}
+
+		return t
+	})
+}
+
+func certificatesGet(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	cert, err := c.session.GetCertificate(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if cert == nil {
+		return fmt.Errorf("Certificate not found: %v", args[0])
+	}
+
+	return c.print(cert, fieldsTable(cert))
+}
+
+func certificatesUpload(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 2)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(args[1])
+
+	if err != nil {
+		return err
+	}
+
+	cert, err := c.session.UploadCertificatePEM(args[0], data)
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(cert, fieldsTable(cert))
+}
+
+func certificatesDownload(c *cli, fs *flag.FlagSet) error {
+	out := fs.String("out", "", "File to write the certificate to, instead of the standard output")
+
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := c.session.DownloadCertificate(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		return ioutil.WriteFile(*out, data, 0600)
+	}
+
+	_, err = c.stdout.Write(data)
+
+	return err
+}
+
+func certificatesDelete(c *cli, fs *flag.FlagSet) error {
+	args, err := c.parse(fs, 1)
+
+	if err != nil {
+		return err
+	}
+
+	deleted, err := c.session.DeleteCertificate(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Certificate not found: %v", args[0])
+	}
+
+	return c.done("Certificate %v deleted", args[0])
+}
+
+//
+// System
+//
+
+func version(c *cli, fs *flag.FlagSet) error {
+	_, err := c.parse(fs, 0)
+
+	if err != nil {
+		return err
+	}
+
+	version, err := c.session.GetSystemVersion()
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(version, fieldsTable(version))
+}
+
+//
+// Helpers
+//
+
+func parseNumber(name string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %v: %v", name, value)
+	}
+
+	return n, nil
+}
+
+func optionalNumber(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
@@ -0,0 +1,296 @@
+// Command zevenetctl manages a Zevenet loadbalancer from the command line.
+//
+// Usage:
+//
+//	zevenetctl [flags] <command> <action> [arguments]
+//
+// Commands:
+//
+//	farms list|get|create|delete|start|stop|restart
+//	services list|get|create|delete
+//	backends list|add|remove|maintenance
+//	interfaces list|get|create|delete
+//	certificates list|get|upload|download|delete
+//	version
+//
+// The output is a table by default, use -output json or -output yaml for scripting.
+//
+// The loadbalancer is selected using -host and the ZAPI key using the environment variable ZAPI_KEY,
+// or using a profile in the file ~/.zevenetctl.yaml:
+//
+//	default: production
+//	profiles:
+//	  production:
+//	    host: lb1.example.com:444
+//	    zapi_key: ...
+//	    fingerprint: AB:CD:EF:...
+//	  staging:
+//	    host: lb-staging.example.com:444
+//	    insecure: true
+//	    timeout: 1m
+//
+// The profile is selected using -profile or the environment variable ZEVENETCTL_PROFILE.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "zevenetctl: %v\n", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// cli keeps the state of a single invocation.
+type cli struct {
+	session *zevenetlb.ZapiSession
+	output  string
+	stdout  io.Writer
+	stderr  io.Writer
+	args    []string
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("zevenetctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	configFile := flags.String("config", defaultConfigFile(), "File containing the profiles")
+	profileName := flags.String("profile", os.Getenv("ZEVENETCTL_PROFILE"), "Profile of the loadbalancer to use")
+	host := flags.String("host", "", "Hostname and port of the loadbalancer, e.g. myloadbalancer:444")
+	fingerprint := flags.String("fingerprint", "", "SHA-256 fingerprint of the loadbalancer's certificate")
+	insecure := flags.Bool("insecure", false, "Disable the verification of the loadbalancer's certificate")
+	timeout := flags.Duration("timeout", 0, "Timeout of a single ZAPI call")
+	output := flags.String("output", "table", "Output format: table, json, or yaml")
+
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: zevenetctl [flags] <command> <action> [arguments]\n\nCommands:\n")
+
+		var names []string
+
+		for name := range commands {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(stderr, "  %v %v\n", name, strings.Join(commands[name].actionNames(), "|"))
+		}
+
+		fmt.Fprintf(stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+
+	if err != nil {
+		return err
+	}
+
+	if *output != "table" && *output != "json" && *output != "yaml" {
+		return fmt.Errorf("Unknown output format: %v", *output)
+	}
+
+	// find the command
+	if flags.NArg() <= 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("Unknown command: %v", flags.Arg(0))
+	}
+
+	name, act, err := cmd.find(flags.Arg(0), flags.Args()[1:])
+
+	if err != nil {
+		return err
+	}
+
+	// connect to the loadbalancer
+	profile, err := loadProfile(*configFile, *profileName)
+
+	if err != nil {
+		return err
+	}
+
+	if *host != "" {
+		profile.Host = *host
+	}
+
+	if *fingerprint != "" {
+		profile.Fingerprint = *fingerprint
+	}
+
+	if *insecure {
+		profile.Insecure = true
+	}
+
+	if *timeout > 0 {
+		profile.Timeout = *timeout
+	}
+
+	session, err := profile.connect()
+
+	if err != nil {
+		return err
+	}
+
+	c := &cli{
+		session: session,
+		output:  *output,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+
+	// run the action
+	fs := flag.NewFlagSet("zevenetctl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: zevenetctl %v %v\n", name, act.usage)
+		fs.PrintDefaults()
+	}
+
+	if cmd.actions == nil {
+		c.args = flags.Args()[1:]
+	} else {
+		c.args = flags.Args()[2:]
+	}
+
+	return act.run(c, fs)
+}
+
+// parse parses the flags of an action and checks the number of arguments.
+func (c *cli) parse(fs *flag.FlagSet, count int) ([]string, error) {
+	err := fs.Parse(c.args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != count {
+		fs.Usage()
+		return nil, fmt.Errorf("Expected %v arguments, got %v", count, fs.NArg())
+	}
+
+	return fs.Args(), nil
+}
+
+//
+// Commands
+//
+
+type action struct {
+	usage string
+	run   func(c *cli, fs *flag.FlagSet) error
+}
+
+type command struct {
+	// actions is nil for commands without actions, e.g. "version".
+	actions map[string]action
+
+	run action
+}
+
+func (cmd command) actionNames() []string {
+	var names []string
+
+	for name := range cmd.actions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// find returns the action to run and its full name, e.g. "farms list".
+func (cmd command) find(name string, args []string) (string, action, error) {
+	if cmd.actions == nil {
+		return name, cmd.run, nil
+	}
+
+	if len(args) <= 0 {
+		return "", action{}, fmt.Errorf("Missing action: zevenetctl %v %v", name, strings.Join(cmd.actionNames(), "|"))
+	}
+
+	act, ok := cmd.actions[args[0]]
+
+	if !ok {
+		return "", action{}, fmt.Errorf("Unknown action: zevenetctl %v %v", name, strings.Join(cmd.actionNames(), "|"))
+	}
+
+	return name + " " + args[0], act, nil
+}
+
+var commands = map[string]command{
+	"farms": {actions: map[string]action{
+		"list":    {"", farmsList},
+		"get":     {"<farm>", farmsGet},
+		"create":  {"[-https] [-certificate <file>] <farm> <virtual-ip> <virtual-port>", farmsCreate},
+		"delete":  {"<farm>", farmsDelete},
+		"start":   {"<farm>", farmsStart},
+		"stop":    {"<farm>", farmsStop},
+		"restart": {"<farm>", farmsRestart},
+	}},
+	"services": {actions: map[string]action{
+		"list":   {"<farm>", servicesList},
+		"get":    {"<farm> <service>", servicesGet},
+		"create": {"<farm> <service>", servicesCreate},
+		"delete": {"<farm> <service>", servicesDelete},
+	}},
+	"backends": {actions: map[string]action{
+		"list":        {"<farm> <service>", backendsList},
+		"add":         {"[-weight <weight>] [-timeout <seconds>] <farm> <service> <ip> <port>", backendsAdd},
+		"remove":      {"<farm> <service> <backend-id>", backendsRemove},
+		"maintenance": {"[-cut] <farm> <service> <backend-id> on|off", backendsMaintenance},
+	}},
+	"interfaces": {actions: map[string]action{
+		"list":   {"", interfacesList},
+		"get":    {"<virtual-interface>", interfacesGet},
+		"create": {"<virtual-interface> <ip>", interfacesCreate},
+		"delete": {"<virtual-interface>", interfacesDelete},
+	}},
+	"certificates": {actions: map[string]action{
+		"list":     {"", certificatesList},
+		"get":      {"<file>", certificatesGet},
+		"upload":   {"<file> <pem-file>", certificatesUpload},
+		"download": {"[-out <file>] <file>", certificatesDownload},
+		"delete":   {"<file>", certificatesDelete},
+	}},
+	"version": {run: action{"", version}},
+}
+
+// defaultConfigFile returns the file containing the profiles, which is ~/.zevenetctl.yaml by default.
+func defaultConfigFile() string {
+	if file := os.Getenv("ZEVENETCTL_CONFIG"); file != "" {
+		return file
+	}
+
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".zevenetctl.yaml")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+// createTestProfiles starts a fake loadbalancer and writes a profiles file selecting it by default.
+func createTestProfiles(t *testing.T) string {
+	server := zevenettest.NewServer()
+	server.LoadSampleData()
+
+	t.Cleanup(server.Close)
+
+	file, err := ioutil.TempFile("", "zevenetctl-*.yaml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.Remove(file.Name()) })
+
+	_, err = fmt.Fprintf(file, "default: fake\nprofiles:\n  fake:\n    host: %v\n    zapi_key: %v\n", server.URL, server.ZapiKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file.Name()
+}
+
+func runTest(t *testing.T, profiles string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	err := run(append([]string{"-config", profiles}, args...), &stdout, &stderr)
+
+	t.Logf("zevenetctl %v:\n%v%v", strings.Join(args, " "), stdout.String(), stderr.String())
+
+	return stdout.String(), err
+}
+
+func TestFarms(t *testing.T) {
+	profiles := createTestProfiles(t)
+
+	out, err := runTest(t, profiles, "farms", "list")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "samplefarm") {
+		t.Fatalf("Unexpected output: %v", out)
+	}
+
+	// create a farm with a backend
+	_, err = runTest(t, profiles, "farms", "create", "webfarm", "10.209.0.31", "80")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runTest(t, profiles, "services", "create", "webfarm", "default")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runTest(t, profiles, "backends", "add", "-weight", "2", "webfarm", "default", "10.209.0.101", "8080")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runTest(t, profiles, "-output", "json", "backends", "list", "webfarm", "default")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backends []struct {
+		ID     int    `json:"id"`
+		IP     string `json:"ip"`
+		Weight int    `json:"weight"`
+	}
+
+	err = json.Unmarshal([]byte(out), &backends)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backends) != 1 || backends[0].IP != "10.209.0.101" || backends[0].Weight != 2 {
+		t.Fatalf("Unexpected backends: %v", backends)
+	}
+
+	_, err = runTest(t, profiles, "backends", "maintenance", "webfarm", "default", fmt.Sprint(backends[0].ID), "on")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runTest(t, profiles, "-output", "yaml", "services", "get", "webfarm", "default")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "status: maintenance") {
+		t.Fatalf("Unexpected output: %v", out)
+	}
+
+	_, err = runTest(t, profiles, "farms", "delete", "webfarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = runTest(t, profiles, "farms", "get", "webfarm")
+
+	if err == nil || err.Error() != "Farm not found: webfarm" {
+		t.Fatalf("Expected the farm to be deleted, got %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	profiles := createTestProfiles(t)
+
+	out, err := runTest(t, profiles, "version")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "zevenet_version") {
+		t.Fatalf("Unexpected output: %v", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	profiles := createTestProfiles(t)
+
+	_, err := runTest(t, profiles, "farms", "explode")
+
+	if err == nil || !strings.HasPrefix(err.Error(), "Unknown action") {
+		t.Fatalf("Expected an unknown action, got %v", err)
+	}
+
+	_, err = runTest(t, profiles, "farms", "get")
+
+	if err == nil || err.Error() != "Expected 1 arguments, got 0" {
+		t.Fatalf("Expected missing arguments, got %v", err)
+	}
+
+	_, err = runTest(t, profiles, "-profile", "missing", "farms", "list")
+
+	if err == nil || err.Error() != "Profile not found: missing" {
+		t.Fatalf("Expected a missing profile, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// table is the tabular output of a command.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	var row []string
+
+	for _, v := range values {
+		row = append(row, fmt.Sprint(v))
+	}
+
+	t.rows = append(t.rows, row)
+}
+
+// print writes the value as JSON or YAML, or the table created by the callback.
+func (c *cli) print(value interface{}, toTable func() *table) error {
+	switch c.output {
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(c.stdout, "%s\n", data)
+
+		return err
+	case "yaml":
+		// use the field names of the ZAPI
+		data, err := json.Marshal(value)
+
+		if err != nil {
+			return err
+		}
+
+		var generic interface{}
+
+		err = json.Unmarshal(data, &generic)
+
+		if err != nil {
+			return err
+		}
+
+		encoder := yaml.NewEncoder(c.stdout)
+		encoder.SetIndent(2)
+
+		err = encoder.Encode(generic)
+
+		if err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	}
+
+	t := toTable()
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// fieldsTable lists the settings of an entity, omitting nested lists and objects.
+func fieldsTable(value interface{}) func() *table {
+	return func() *table {
+		t := &table{header: []string{"FIELD", "VALUE"}}
+
+		data, err := json.Marshal(value)
+
+		if err != nil {
+			return t
+		}
+
+		var fields map[string]interface{}
+
+		err = json.Unmarshal(data, &fields)
+
+		if err != nil {
+			return t
+		}
+
+		var names []string
+
+		for name, v := range fields {
+			switch v.(type) {
+			case []interface{}, map[string]interface{}:
+				continue
+			}
+
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if fields[name] == nil {
+				t.add(name, "")
+			} else {
+				t.add(name, fields[name])
+			}
+		}
+
+		return t
+	}
+}
+
+// done reports a completed change, in table output only.
+func (c *cli) done(format string, args ...interface{}) error {
+	if c.output != "table" {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(c.stdout, format+"\n", args...)
+
+	return err
+}
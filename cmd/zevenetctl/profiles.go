@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"gopkg.in/yaml.v3"
+)
+
+// profilesFile is the file containing the profiles of the loadbalancers.
+type profilesFile struct {
+	Default  string              `yaml:"default"`
+	Profiles map[string]*profile `yaml:"profiles"`
+}
+
+// profile contains the connection settings of a loadbalancer.
+type profile struct {
+	Host        string        `yaml:"host"`
+	ZapiKey     string        `yaml:"zapi_key"`
+	Fingerprint string        `yaml:"fingerprint"`
+	Insecure    bool          `yaml:"insecure"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// loadProfile reads a profile from the file. If no profile is selected and the file has no default,
+// the environment variables ZAPI_HOSTNAME, ZAPI_KEY, and ZAPI_FINGERPRINT are used.
+func loadProfile(filename string, name string) (*profile, error) {
+	var file profilesFile
+
+	if filename != "" {
+		data, err := ioutil.ReadFile(filename)
+
+		if err != nil && !(os.IsNotExist(err) && name == "") {
+			return nil, fmt.Errorf("Failed to read the profiles: %v", err)
+		}
+
+		err = yaml.Unmarshal(data, &file)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the profiles from %v: %v", filename, err)
+		}
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+
+	// use the environment
+	if name == "" {
+		return &profile{
+			Host:        os.Getenv("ZAPI_HOSTNAME"),
+			ZapiKey:     os.Getenv("ZAPI_KEY"),
+			Fingerprint: os.Getenv("ZAPI_FINGERPRINT"),
+		}, nil
+	}
+
+	p, ok := file.Profiles[name]
+
+	if !ok || p == nil {
+		return nil, fmt.Errorf("Profile not found: %v", name)
+	}
+
+	// keep the key out of the file
+	if p.ZapiKey == "" {
+		p.ZapiKey = os.Getenv("ZAPI_KEY")
+	}
+
+	return p, nil
+}
+
+// connect connects to the loadbalancer of the profile.
+func (p *profile) connect() (*zevenetlb.ZapiSession, error) {
+	if p.Host == "" || p.ZapiKey == "" {
+		return nil, fmt.Errorf("The loadbalancer has to be set using -host or a profile, and the ZAPI key using the environment variable ZAPI_KEY or a profile")
+	}
+
+	session, err := zevenetlb.Connect(p.Host, p.ZapiKey, &zevenetlb.ConfigOptions{
+		APICallTimeout:        p.Timeout,
+		TLSPinnedFingerprint:  p.Fingerprint,
+		TLSInsecureSkipVerify: p.Insecure,
+		RetryPolicy:           zevenetlb.DefaultRetryPolicy(),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to %v: %v", p.Host, err)
+	}
+
+	return session, nil
+}
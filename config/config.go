@@ -0,0 +1,431 @@
+// Package config loads the configuration of a Zevenet loadbalancer from YAML or JSON files,
+// e.g. to keep the farms in a git repository and apply them using *ZapiSession.Reconcile()*.
+//
+// A configuration file contains virtual interfaces, certificates, and HTTP/S farms including their
+// services and backends. All sections and most fields are optional:
+//
+//	interfaces:
+//	  - name: eth0:web            # <nic>:<name>
+//	    ip: 10.0.0.10
+//
+//	certificates:
+//	  - file: web.pem             # name on the loadbalancer, has to end with .pem
+//	    path: certs/web.pem       # local PEM bundle, relative to the configuration file
+//
+//	farms:
+//	  - name: web
+//	    listener: https           # http (default) or https
+//	    virtual_ip: 10.0.0.10
+//	    virtual_port: 443
+//	    certificates: [web.pem]   # https only, in order of preference
+//	    ciphers: highsecurity     # all, highsecurity, or customsecurity
+//	    ciphers_custom: ""
+//	    disable_sslv2: true
+//	    disable_sslv3: true
+//	    disable_tlsv1: false
+//	    disable_tlsv1_1: false
+//	    disable_tlsv1_2: false
+//	    http_verbs: extendedHTTP  # standardHTTP, extendedHTTP, standardWebDAV, MSextWebDAV, or MSRPCext
+//	    rewrite_location: disabled # enabled, enabled-backends, or disabled
+//	    connection_timeout: 20    # seconds
+//	    request_timeout: 30
+//	    response_timeout: 45
+//	    resurrect_interval: 10
+//	    error_414: ""
+//	    error_500: ""
+//	    error_501: ""
+//	    error_503: Service unavailable
+//	    services:
+//	      - name: default
+//	        host_pattern: www.example.com
+//	        url_pattern: ""
+//	        redirect_url: ""
+//	        redirect_type: ""     # default or append, if redirect_url is set
+//	        encrypted_backends: false
+//	        least_response: false
+//	        persistence: COOKIE   # IP, BASIC, URL, PARM, COOKIE, or HEADER
+//	        persistence_id: SESSIONID
+//	        persistence_timeout: 120
+//	        farmguardian:
+//	          enabled: true
+//	          logs: false
+//	          script: check_http -H HOST -p PORT
+//	          interval: 10
+//	        backends:
+//	          - ip: 10.0.1.1
+//	            port: 8080
+//	          - ip: 10.0.1.2
+//	            port: 8080
+//	            weight: 2
+//	            timeout: 30
+//
+// JSON files use the same field names. Validation errors contain the file name and line number.
+// Omitted fields keep the current setting of the loadbalancer, including booleans: set them to false explicitly
+// to turn a setting off.
+//
+// Use *Export()* to retrieve the configuration of a loadbalancer in the same format.
+package config
+
+import (
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+// Config is the desired configuration of a loadbalancer.
+type Config struct {
+	VirtualInterfaces []zevenetlb.VirtualInterfaceDetails
+	Certificates      []Certificate
+	Farms             []zevenetlb.FarmDetails
+
+	// Flags contains the boolean settings set explicitly in the file, or all of them if exported, by farm name.
+	// The boolean fields of *Farms* cannot tell *false* apart from an omitted setting.
+	Flags map[string]zevenetlb.FarmFlags
+
+	// SkippedFarms contains the names of the farms not exported by *Export()*, as the file format
+	// supports HTTP and HTTPS farms only, e.g. L4xNAT and DATALINK farms. It is not saved.
+	SkippedFarms []string
+}
+
+// Certificate is a certificate on the loadbalancer.
+type Certificate struct {
+	// Filename is the name of the certificate on the loadbalancer, e.g. "web.pem".
+	Filename string
+
+	// Path is the local PEM bundle to upload, relative paths are resolved against the configuration file.
+	// It is empty, if the certificate is managed otherwise.
+	Path string
+}
+
+// GetFarm retrieves a farm by its name, or returns *nil* if not found.
+func (c *Config) GetFarm(farmName string) *zevenetlb.FarmDetails {
+	for i := range c.Farms {
+		if c.Farms[i].FarmName == farmName {
+			return &c.Farms[i]
+		}
+	}
+
+	return nil
+}
+
+// FarmSpecs returns the farms as input for *ZapiSession.Reconcile()*.
+func (c *Config) FarmSpecs() []*zevenetlb.FarmSpec {
+	var specs []*zevenetlb.FarmSpec
+
+	for _, f := range c.Farms {
+		specs = append(specs, &zevenetlb.FarmSpec{Farm: f, Flags: c.Flags[f.FarmName]})
+	}
+
+	return specs
+}
+
+//
+// File Format
+//
+
+type fileFormat struct {
+	VirtualInterfaces []*interfaceFormat   `yaml:"interfaces,omitempty" json:"interfaces,omitempty"`
+	Certificates      []*certificateFormat `yaml:"certificates,omitempty" json:"certificates,omitempty"`
+	Farms             []*farmFormat        `yaml:"farms,omitempty" json:"farms,omitempty"`
+
+	pos position
+}
+
+type interfaceFormat struct {
+	Name string `yaml:"name" json:"name"`
+	IP   string `yaml:"ip" json:"ip"`
+
+	pos position
+}
+
+type certificateFormat struct {
+	File string `yaml:"file" json:"file"`
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	pos position
+}
+
+type farmFormat struct {
+	Name              string           `yaml:"name" json:"name"`
+	Listener          string           `yaml:"listener,omitempty" json:"listener,omitempty"`
+	VirtualIP         string           `yaml:"virtual_ip" json:"virtual_ip"`
+	VirtualPort       int              `yaml:"virtual_port,omitempty" json:"virtual_port,omitempty"`
+	Certificates      []string         `yaml:"certificates,omitempty" json:"certificates,omitempty"`
+	Ciphers           string           `yaml:"ciphers,omitempty" json:"ciphers,omitempty"`
+	CiphersCustom     string           `yaml:"ciphers_custom,omitempty" json:"ciphers_custom,omitempty"`
+	DisableSSLv2      *bool            `yaml:"disable_sslv2,omitempty" json:"disable_sslv2,omitempty"`
+	DisableSSLv3      *bool            `yaml:"disable_sslv3,omitempty" json:"disable_sslv3,omitempty"`
+	DisableTLSv1      *bool            `yaml:"disable_tlsv1,omitempty" json:"disable_tlsv1,omitempty"`
+	DisableTLSv11     *bool            `yaml:"disable_tlsv1_1,omitempty" json:"disable_tlsv1_1,omitempty"`
+	DisableTLSv12     *bool            `yaml:"disable_tlsv1_2,omitempty" json:"disable_tlsv1_2,omitempty"`
+	HTTPVerbs         string           `yaml:"http_verbs,omitempty" json:"http_verbs,omitempty"`
+	RewriteLocation   string           `yaml:"rewrite_location,omitempty" json:"rewrite_location,omitempty"`
+	ConnectionTimeout int              `yaml:"connection_timeout,omitempty" json:"connection_timeout,omitempty"`
+	RequestTimeout    int              `yaml:"request_timeout,omitempty" json:"request_timeout,omitempty"`
+	ResponseTimeout   int              `yaml:"response_timeout,omitempty" json:"response_timeout,omitempty"`
+	ResurrectInterval int              `yaml:"resurrect_interval,omitempty" json:"resurrect_interval,omitempty"`
+	Error414          string           `yaml:"error_414,omitempty" json:"error_414,omitempty"`
+	Error500          string           `yaml:"error_500,omitempty" json:"error_500,omitempty"`
+	Error501          string           `yaml:"error_501,omitempty" json:"error_501,omitempty"`
+	Error503          string           `yaml:"error_503,omitempty" json:"error_503,omitempty"`
+	Services          []*serviceFormat `yaml:"services,omitempty" json:"services,omitempty"`
+
+	pos position
+}
+
+type serviceFormat struct {
+	Name               string              `yaml:"name" json:"name"`
+	HostPattern        string              `yaml:"host_pattern,omitempty" json:"host_pattern,omitempty"`
+	URLPattern         string              `yaml:"url_pattern,omitempty" json:"url_pattern,omitempty"`
+	RedirectURL        string              `yaml:"redirect_url,omitempty" json:"redirect_url,omitempty"`
+	RedirectType       string              `yaml:"redirect_type,omitempty" json:"redirect_type,omitempty"`
+	EncryptedBackends  *bool               `yaml:"encrypted_backends,omitempty" json:"encrypted_backends,omitempty"`
+	LeastResponse      *bool               `yaml:"least_response,omitempty" json:"least_response,omitempty"`
+	Persistence        string              `yaml:"persistence,omitempty" json:"persistence,omitempty"`
+	PersistenceID      string              `yaml:"persistence_id,omitempty" json:"persistence_id,omitempty"`
+	PersistenceTimeout int                 `yaml:"persistence_timeout,omitempty" json:"persistence_timeout,omitempty"`
+	FarmGuardian       *farmGuardianFormat `yaml:"farmguardian,omitempty" json:"farmguardian,omitempty"`
+	Backends           []*backendFormat    `yaml:"backends,omitempty" json:"backends,omitempty"`
+
+	pos position
+}
+
+type farmGuardianFormat struct {
+	Enabled  *bool  `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Logs     *bool  `yaml:"logs,omitempty" json:"logs,omitempty"`
+	Script   string `yaml:"script,omitempty" json:"script,omitempty"`
+	Interval int    `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	pos position
+}
+
+type backendFormat struct {
+	IP      string `yaml:"ip" json:"ip"`
+	Port    int    `yaml:"port" json:"port"`
+	Weight  *int   `yaml:"weight,omitempty" json:"weight,omitempty"`
+	Timeout *int   `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	pos position
+}
+
+func (f *farmFormat) toFarmDetails() zevenetlb.FarmDetails {
+	farm := zevenetlb.FarmDetails{
+		FarmName:                 f.Name,
+		Listener:                 zevenetlb.FarmListener(f.Listener),
+		VirtualIP:                f.VirtualIP,
+		VirtualPort:              f.VirtualPort,
+		Ciphers:                  zevenetlb.FarmCiphers(f.Ciphers),
+		CiphersCustom:            f.CiphersCustom,
+		DisableSSLv2:             isTrue(f.DisableSSLv2),
+		DisableSSLv3:             isTrue(f.DisableSSLv3),
+		DisableTLSv1:             isTrue(f.DisableTLSv1),
+		DisableTLSv11:            isTrue(f.DisableTLSv11),
+		DisableTLSv12:            isTrue(f.DisableTLSv12),
+		HTTPVerbs:                zevenetlb.FarmHTTPVerb(f.HTTPVerbs),
+		RewriteLocation:          zevenetlb.FarmRewriteLocation(f.RewriteLocation),
+		ConnectionTimeoutSeconds: f.ConnectionTimeout,
+		RequestTimeoutSeconds:    f.RequestTimeout,
+		ResponseTimeoutSeconds:   f.ResponseTimeout,
+		ResurrectIntervalSeconds: f.ResurrectInterval,
+		ErrorString414:           f.Error414,
+		ErrorString500:           f.Error500,
+		ErrorString501:           f.Error501,
+		ErrorString503:           f.Error503,
+	}
+
+	for i, c := range f.Certificates {
+		farm.Certificates = append(farm.Certificates, zevenetlb.CertificateInfo{Filename: c, ID: i + 1})
+	}
+
+	for _, s := range f.Services {
+		farm.Services = append(farm.Services, s.toServiceDetails(f.Name))
+	}
+
+	return farm
+}
+
+// toFarmFlags returns the boolean settings set explicitly.
+func (f *farmFormat) toFarmFlags() zevenetlb.FarmFlags {
+	flags := zevenetlb.FarmFlags{
+		DisableSSLv2:  toOptionalBool(f.DisableSSLv2),
+		DisableSSLv3:  toOptionalBool(f.DisableSSLv3),
+		DisableTLSv1:  toOptionalBool(f.DisableTLSv1),
+		DisableTLSv11: toOptionalBool(f.DisableTLSv11),
+		DisableTLSv12: toOptionalBool(f.DisableTLSv12),
+		Services:      map[string]zevenetlb.ServiceFlags{},
+	}
+
+	for _, s := range f.Services {
+		serviceFlags := zevenetlb.ServiceFlags{
+			EncryptedBackends:            toOptionalBool(s.EncryptedBackends),
+			LastResponseBalancingEnabled: toOptionalBool(s.LeastResponse),
+		}
+
+		if s.FarmGuardian != nil {
+			serviceFlags.FarmGuardianEnabled = toOptionalBool(s.FarmGuardian.Enabled)
+		}
+
+		flags.Services[s.Name] = serviceFlags
+	}
+
+	return flags
+}
+
+func (s *serviceFormat) toServiceDetails(farmName string) zevenetlb.ServiceDetails {
+	service := zevenetlb.ServiceDetails{
+		ServiceName:                         s.Name,
+		HostPattern:                         s.HostPattern,
+		URLPattern:                          s.URLPattern,
+		RedirectURL:                         s.RedirectURL,
+		RedirectType:                        zevenetlb.ServiceRedirectType(s.RedirectType),
+		EncryptedBackends:                   isTrue(s.EncryptedBackends),
+		LastResponseBalancingEnabled:        isTrue(s.LeastResponse),
+		ConnectionPersistenceMode:           zevenetlb.ServiceConnPersistenceMode(s.Persistence),
+		ConnectionPersistenceID:             s.PersistenceID,
+		ConnectionPersistenceTimeoutSeconds: s.PersistenceTimeout,
+		FarmName:                            farmName,
+	}
+
+	if fg := s.FarmGuardian; fg != nil {
+		service.FarmGuardianEnabled = isTrue(fg.Enabled)
+		service.FarmGuardianLogsEnabled = toOptionalBool(fg.Logs)
+		service.FarmGuardianScript = fg.Script
+		service.FarmGuardianCheckIntervalSeconds = fg.Interval
+	}
+
+	for _, b := range s.Backends {
+		service.Backends = append(service.Backends, zevenetlb.BackendDetails{
+			IPAddress:      b.IP,
+			Port:           b.Port,
+			Weight:         b.Weight,
+			TimeoutSeconds: b.Timeout,
+			FarmName:       farmName,
+			ServiceName:    s.Name,
+		})
+	}
+
+	return service
+}
+
+func (c *Config) toFileFormat() *fileFormat {
+	file := &fileFormat{}
+
+	for _, vi := range c.VirtualInterfaces {
+		file.VirtualInterfaces = append(file.VirtualInterfaces, &interfaceFormat{
+			Name: vi.Name,
+			IP:   vi.IP,
+		})
+	}
+
+	for _, cert := range c.Certificates {
+		file.Certificates = append(file.Certificates, &certificateFormat{
+			File: cert.Filename,
+			Path: cert.Path,
+		})
+	}
+
+	for i := range c.Farms {
+		file.Farms = append(file.Farms, fromFarmDetails(&c.Farms[i], c.Flags[c.Farms[i].FarmName]))
+	}
+
+	return file
+}
+
+// fromFarmDetails converts a farm, the *flags* override its boolean fields.
+func fromFarmDetails(farm *zevenetlb.FarmDetails, flags zevenetlb.FarmFlags) *farmFormat {
+	f := &farmFormat{
+		Name:              farm.FarmName,
+		Listener:          string(farm.Listener),
+		VirtualIP:         farm.VirtualIP,
+		VirtualPort:       farm.VirtualPort,
+		Ciphers:           string(farm.Ciphers),
+		CiphersCustom:     farm.CiphersCustom,
+		DisableSSLv2:      fromFlag(flags.DisableSSLv2, farm.DisableSSLv2),
+		DisableSSLv3:      fromFlag(flags.DisableSSLv3, farm.DisableSSLv3),
+		DisableTLSv1:      fromFlag(flags.DisableTLSv1, farm.DisableTLSv1),
+		DisableTLSv11:     fromFlag(flags.DisableTLSv11, farm.DisableTLSv11),
+		DisableTLSv12:     fromFlag(flags.DisableTLSv12, farm.DisableTLSv12),
+		HTTPVerbs:         string(farm.HTTPVerbs),
+		RewriteLocation:   string(farm.RewriteLocation),
+		ConnectionTimeout: farm.ConnectionTimeoutSeconds,
+		RequestTimeout:    farm.RequestTimeoutSeconds,
+		ResponseTimeout:   farm.ResponseTimeoutSeconds,
+		ResurrectInterval: farm.ResurrectIntervalSeconds,
+		Error414:          farm.ErrorString414,
+		Error500:          farm.ErrorString500,
+		Error501:          farm.ErrorString501,
+		Error503:          farm.ErrorString503,
+	}
+
+	for _, c := range farm.Certificates {
+		f.Certificates = append(f.Certificates, c.Filename)
+	}
+
+	for i := range farm.Services {
+		f.Services = append(f.Services, fromServiceDetails(&farm.Services[i], flags.Services[farm.Services[i].ServiceName]))
+	}
+
+	return f
+}
+
+func fromServiceDetails(service *zevenetlb.ServiceDetails, flags zevenetlb.ServiceFlags) *serviceFormat {
+	s := &serviceFormat{
+		Name:               service.ServiceName,
+		HostPattern:        service.HostPattern,
+		URLPattern:         service.URLPattern,
+		RedirectURL:        service.RedirectURL,
+		RedirectType:       string(service.RedirectType),
+		EncryptedBackends:  fromFlag(flags.EncryptedBackends, service.EncryptedBackends),
+		LeastResponse:      fromFlag(flags.LastResponseBalancingEnabled, service.LastResponseBalancingEnabled),
+		Persistence:        string(service.ConnectionPersistenceMode),
+		PersistenceID:      service.ConnectionPersistenceID,
+		PersistenceTimeout: service.ConnectionPersistenceTimeoutSeconds,
+	}
+
+	// farmguardian
+	fg := &farmGuardianFormat{
+		Enabled:  fromFlag(flags.FarmGuardianEnabled, service.FarmGuardianEnabled),
+		Logs:     fromFlag(service.FarmGuardianLogsEnabled, false),
+		Script:   service.FarmGuardianScript,
+		Interval: service.FarmGuardianCheckIntervalSeconds,
+	}
+
+	if fg.Enabled != nil || fg.Logs != nil || fg.Script != "" || fg.Interval != 0 {
+		s.FarmGuardian = fg
+	}
+
+	for _, b := range service.Backends {
+		s.Backends = append(s.Backends, &backendFormat{
+			IP:      b.IPAddress,
+			Port:    b.Port,
+			Weight:  b.Weight,
+			Timeout: b.TimeoutSeconds,
+		})
+	}
+
+	return s
+}
+
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
+
+func toOptionalBool(value *bool) zevenetlb.OptionalBool {
+	switch {
+	case value == nil:
+		return zevenetlb.OptionalBool_Nil
+	case *value:
+		return zevenetlb.OptionalBool_True
+	default:
+		return zevenetlb.OptionalBool_False
+	}
+}
+
+// fromFlag returns the flag, if set, or *true* if the value is set, or *nil* otherwise.
+func fromFlag(flag zevenetlb.OptionalBool, value bool) *bool {
+	if flag == zevenetlb.OptionalBool_Nil && !value {
+		return nil
+	}
+
+	result := flag == zevenetlb.OptionalBool_True || (flag == zevenetlb.OptionalBool_Nil && value)
+
+	return &result
+}
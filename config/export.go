@@ -0,0 +1,160 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Export retrieves the configuration of a loadbalancer, e.g. to bootstrap a configuration file
+// from an appliance configured by hand, or to detect drift.
+//
+// Farms, interfaces, and certificates are sorted by name, so exports of the same configuration are identical.
+// Services, backends, and the certificates of farms keep their order, as it is significant.
+// Only HTTP/S farms are exported, the names of other farms, e.g. L4xNAT and DATALINK farms, are listed in
+// *SkippedFarms* instead. CSRs and the contents of certificates are not exported.
+func Export(s *zevenetlb.ZapiSession) (*Config, error) {
+	return ExportContext(context.Background(), s)
+}
+
+// ExportContext retrieves the configuration of a loadbalancer using the provided context.
+func ExportContext(ctx context.Context, s *zevenetlb.ZapiSession) (*Config, error) {
+	config := &Config{Flags: map[string]zevenetlb.FarmFlags{}}
+
+	// retrieve interfaces
+	interfaces, err := s.GetAllVirtualInterfacesContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the virtual interfaces: %w", err)
+	}
+
+	for _, vi := range interfaces {
+		config.VirtualInterfaces = append(config.VirtualInterfaces, zevenetlb.VirtualInterfaceDetails{
+			Name: vi.Name,
+			IP:   vi.IP,
+		})
+	}
+
+	sort.Slice(config.VirtualInterfaces, func(i, j int) bool {
+		return config.VirtualInterfaces[i].Name < config.VirtualInterfaces[j].Name
+	})
+
+	// retrieve certificates
+	certs, err := s.GetAllCertificatesContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the certificates: %w", err)
+	}
+
+	for _, c := range certs {
+		if !strings.HasSuffix(c.Filename, ".pem") {
+			continue
+		}
+
+		config.Certificates = append(config.Certificates, Certificate{Filename: c.Filename})
+	}
+
+	sort.Slice(config.Certificates, func(i, j int) bool {
+		return config.Certificates[i].Filename < config.Certificates[j].Filename
+	})
+
+	// retrieve farms
+	farms, err := s.GetAllFarmsContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the farms: %w", err)
+	}
+
+	sort.Slice(farms, func(i, j int) bool {
+		return farms[i].FarmName < farms[j].FarmName
+	})
+
+	for _, fi := range farms {
+		if fi.Profile != zevenetlb.FarmProfile_HTTP && fi.Profile != zevenetlb.FarmProfile_HTTPS {
+			config.SkippedFarms = append(config.SkippedFarms, fi.FarmName)
+			continue
+		}
+
+		farm, err := s.GetFarmContext(ctx, fi.FarmName)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve farm %v: %w", fi.FarmName, err)
+		}
+
+		// deleted in the meantime?
+		if farm == nil {
+			continue
+		}
+
+		// booleans are set explicitly to reproduce the farm, including *false*
+		config.Farms = append(config.Farms, *farm)
+		config.Flags[farm.FarmName] = farm.Flags()
+	}
+
+	return config, nil
+}
+
+//
+// Encoding
+//
+
+// MarshalYAML returns the configuration in the file format.
+func (c *Config) MarshalYAML() (interface{}, error) {
+	return c.toFileFormat(), nil
+}
+
+// MarshalJSON returns the configuration in the file format.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toFileFormat())
+}
+
+// WriteYAML writes the configuration in YAML format. Settings having their empty value are omitted, except booleans set in *Flags*.
+func (c *Config) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(c.toFileFormat())
+
+	if err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// WriteJSON writes the configuration in indented JSON format. Settings having their empty value are omitted, except booleans set in *Flags*.
+func (c *Config) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(c.toFileFormat())
+}
+
+// Save writes the configuration to a file, in JSON format if its name ends with ".json", or YAML otherwise.
+func (c *Config) Save(filename string) error {
+	var buffer bytes.Buffer
+
+	var err error
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = c.WriteJSON(&buffer)
+	} else {
+		err = c.WriteYAML(&buffer)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, buffer.Bytes(), 0644)
+}
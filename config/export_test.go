@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+func TestExport(t *testing.T) {
+	server := zevenettest.NewServer()
+	server.LoadSampleData()
+
+	t.Cleanup(server.Close)
+
+	session, err := zevenetlb.Connect(server.URL, server.ZapiKey, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add a second farm to check the ordering
+	_, err = session.CreateFarmAsHTTP("anotherfarm", "10.209.0.31", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// L4xNAT farms are not supported
+	_, err = session.CreateL4xNatFarm("l4farm", "10.209.0.32", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Export(session)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Farms) != 2 || config.Farms[0].FarmName != "anotherfarm" || config.Farms[1].FarmName != "samplefarm" {
+		t.Fatalf("Unexpected farms: %v", config.Farms)
+	}
+
+	if len(config.SkippedFarms) != 1 || config.SkippedFarms[0] != "l4farm" {
+		t.Fatalf("Unexpected skipped farms: %v", config.SkippedFarms)
+	}
+
+	if len(config.VirtualInterfaces) != 1 || config.VirtualInterfaces[0].Name != "eth0:sample" {
+		t.Fatalf("Unexpected interfaces: %v", config.VirtualInterfaces)
+	}
+
+	var buffer bytes.Buffer
+
+	err = config.WriteYAML(&buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Export:\n%v", buffer.String())
+
+	// the export is stable
+	again, err := Export(session)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var againBuffer bytes.Buffer
+
+	err = again.WriteYAML(&againBuffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buffer.String() != againBuffer.String() {
+		t.Fatalf("Export is not stable:\n%v", againBuffer.String())
+	}
+
+	// the export can be loaded again
+	loaded, err := Parse(buffer.Bytes(), "export.yaml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm := loaded.GetFarm("samplefarm")
+
+	if farm == nil || len(farm.Services) != 1 || len(farm.Services[0].Backends) != 1 || farm.Services[0].Backends[0].IPAddress != "10.209.0.100" {
+		t.Fatalf("Unexpected farm: %v", farm)
+	}
+
+	// false is exported explicitly
+	if !strings.Contains(buffer.String(), "disable_sslv2: false") {
+		t.Fatalf("Expected false booleans to be exported:\n%v", buffer.String())
+	}
+
+	// the loaded export matches the loadbalancer
+	for _, spec := range loaded.FarmSpecs() {
+		plan, err := session.PlanReconcile(spec)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !plan.IsEmpty() {
+			t.Fatalf("Unexpected plan: %v", plan)
+		}
+	}
+
+	// the loaded export reproduces the farm, even after a boolean has been changed
+	live, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live.DisableSSLv2 = true
+
+	err = session.UpdateFarm(live)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, spec := range loaded.FarmSpecs() {
+		_, err = session.Reconcile(spec)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		plan, err := session.PlanReconcile(spec)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !plan.IsEmpty() {
+			t.Fatalf("Unexpected plan: %v", plan)
+		}
+	}
+
+	live, err = session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if live.DisableSSLv2 {
+		t.Fatal("Expected disable_sslv2 to be reset to false")
+	}
+
+	// JSON uses the same format
+	data, err := json.Marshal(loaded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Parse(data, "export.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportUnauthorized(t *testing.T) {
+	// the key is accepted on connect, but not for the interfaces
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/system/version") {
+			w.Write([]byte(`{"description":"System version","params":{"appliance_version":"ZCE 5 (v5.0)"}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"description":"Authorization required","message":"Authorization required"}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	session, err := zevenetlb.Connect(server.URL, "zapi-key", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Export(session)
+
+	if !errors.Is(err, zevenetlb.ErrUnauthorized) {
+		t.Fatalf("Expected an authorization error, but got %v", err)
+	}
+}
@@ -0,0 +1,488 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is an invalid setting in a configuration file.
+type ValidationError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// Error returns the location and the message, e.g. "farms.yaml:12: Invalid port: 0".
+func (e *ValidationError) Error() string {
+	if e.Line <= 0 {
+		return fmt.Sprintf("%v: %v", e.File, e.Message)
+	}
+
+	return fmt.Sprintf("%v:%v: %v", e.File, e.Line, e.Message)
+}
+
+// ValidationErrors contains all invalid settings of a configuration file, ordered by line.
+type ValidationErrors []*ValidationError
+
+// Error returns all errors, one per line.
+func (e ValidationErrors) Error() string {
+	var lines []string
+
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationErrors) add(line int, format string, args ...interface{}) {
+	*e = append(*e, &ValidationError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// Load reads and validates a configuration file in YAML or JSON format.
+// If the file is invalid, the returned error is of type *ValidationErrors*.
+func Load(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data, filename)
+}
+
+// Parse validates a configuration in YAML or JSON format. The filename is used in
+// error messages and to resolve the paths of certificates.
+// If the configuration is invalid, the returned error is of type *ValidationErrors*.
+func Parse(data []byte, filename string) (*Config, error) {
+	var file fileFormat
+
+	err := yaml.Unmarshal(data, &file)
+
+	var errs ValidationErrors
+
+	if err != nil {
+		errs = parseErrors(err)
+	} else {
+		errs = file.validate()
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+
+	if len(errs) > 0 {
+		for _, e := range errs {
+			e.File = filename
+		}
+
+		return nil, errs
+	}
+
+	// convert
+	config := &Config{Flags: map[string]zevenetlb.FarmFlags{}}
+
+	for _, vi := range file.VirtualInterfaces {
+		config.VirtualInterfaces = append(config.VirtualInterfaces, zevenetlb.VirtualInterfaceDetails{
+			Name: vi.Name,
+			IP:   vi.IP,
+		})
+	}
+
+	for _, c := range file.Certificates {
+		path := c.Path
+
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filename), path)
+		}
+
+		config.Certificates = append(config.Certificates, Certificate{
+			Filename: c.File,
+			Path:     path,
+		})
+	}
+
+	for _, f := range file.Farms {
+		config.Farms = append(config.Farms, f.toFarmDetails())
+		config.Flags[f.Name] = f.toFarmFlags()
+	}
+
+	return config, nil
+}
+
+var errorLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// parseErrors extracts the line numbers from syntax and type errors.
+func parseErrors(err error) ValidationErrors {
+	var messages []string
+
+	if te, ok := err.(*yaml.TypeError); ok {
+		messages = te.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	var errs ValidationErrors
+
+	for _, msg := range messages {
+		m := errorLinePattern.FindStringSubmatch(msg)
+
+		if m == nil {
+			errs.add(0, "%v", strings.TrimPrefix(msg, "yaml: "))
+			continue
+		}
+
+		line, _ := strconv.Atoi(m[1])
+
+		errs.add(line, "%v", m[2])
+	}
+
+	return errs
+}
+
+//
+// Positions
+//
+
+// position keeps the line of an entry and its keys for validation errors.
+type position struct {
+	line int
+	keys map[string]int
+}
+
+// lineOf returns the line of a key, or of the entry if the key is missing.
+func (p *position) lineOf(key string) int {
+	if line, ok := p.keys[key]; ok {
+		return line
+	}
+
+	return p.line
+}
+
+// decodeMapping decodes a mapping node into the target, recording the positions and rejecting unknown keys.
+func decodeMapping(node *yaml.Node, target interface{}, pos *position) error {
+	if node.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %v: expected a mapping", node.Line)}}
+	}
+
+	known := yamlKeys(reflect.TypeOf(target).Elem())
+	keys := map[string]int{}
+
+	var errors []string
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+
+		if !known[key.Value] {
+			errors = append(errors, fmt.Sprintf("line %v: unknown field %q", key.Line, key.Value))
+			continue
+		}
+
+		keys[key.Value] = key.Line
+	}
+
+	err := node.Decode(target)
+
+	if te, ok := err.(*yaml.TypeError); ok {
+		errors = append(errors, te.Errors...)
+	} else if err != nil {
+		return err
+	}
+
+	*pos = position{line: node.Line, keys: keys}
+
+	if len(errors) > 0 {
+		return &yaml.TypeError{Errors: errors}
+	}
+
+	return nil
+}
+
+// yamlKeys returns the keys of a struct's fields.
+func yamlKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("yaml")
+
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		keys[strings.Split(tag, ",")[0]] = true
+	}
+
+	return keys
+}
+
+func (f *fileFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain fileFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *interfaceFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain interfaceFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *certificateFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain certificateFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *farmFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain farmFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *serviceFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain serviceFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *farmGuardianFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain farmGuardianFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+func (f *backendFormat) UnmarshalYAML(node *yaml.Node) error {
+	type plain backendFormat
+	return decodeMapping(node, (*plain)(f), &f.pos)
+}
+
+//
+// Validation
+//
+
+func (f *fileFormat) validate() ValidationErrors {
+	var errs ValidationErrors
+
+	// interfaces
+	names := map[string]bool{}
+
+	for _, vi := range f.VirtualInterfaces {
+		if vi == nil {
+			errs.add(f.pos.lineOf("interfaces"), "Empty interface")
+			continue
+		}
+
+		switch {
+		case vi.Name == "":
+			errs.add(vi.pos.line, "The interface name is required")
+		case !strings.Contains(vi.Name, ":"):
+			errs.add(vi.pos.lineOf("name"), "Interface %v: the name has to contain the NIC, e.g. eth0:web", vi.Name)
+		case names[vi.Name]:
+			errs.add(vi.pos.lineOf("name"), "Duplicate interface: %v", vi.Name)
+		}
+
+		names[vi.Name] = true
+
+		if net.ParseIP(vi.IP) == nil {
+			errs.add(vi.pos.lineOf("ip"), "Interface %v: invalid IP address: %q", vi.Name, vi.IP)
+		}
+	}
+
+	// certificates
+	names = map[string]bool{}
+
+	for _, c := range f.Certificates {
+		if c == nil {
+			errs.add(f.pos.lineOf("certificates"), "Empty certificate")
+			continue
+		}
+
+		switch {
+		case c.File == "":
+			errs.add(c.pos.line, "The certificate file is required")
+		case !strings.HasSuffix(c.File, ".pem"):
+			errs.add(c.pos.lineOf("file"), "Certificate %v: the file has to end with .pem", c.File)
+		case names[c.File]:
+			errs.add(c.pos.lineOf("file"), "Duplicate certificate: %v", c.File)
+		}
+
+		names[c.File] = true
+	}
+
+	// farms
+	names = map[string]bool{}
+
+	for _, farm := range f.Farms {
+		if farm == nil {
+			errs.add(f.pos.lineOf("farms"), "Empty farm")
+			continue
+		}
+
+		if farm.Name == "" {
+			errs.add(farm.pos.line, "The farm name is required")
+		} else if names[farm.Name] {
+			errs.add(farm.pos.lineOf("name"), "Duplicate farm: %v", farm.Name)
+		}
+
+		names[farm.Name] = true
+
+		errs = append(errs, farm.validate()...)
+	}
+
+	return errs
+}
+
+func (f *farmFormat) validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if !isOneOf(f.Listener, "", string(zevenetlb.FarmListener_HTTP), string(zevenetlb.FarmListener_HTTPS)) {
+		errs.add(f.pos.lineOf("listener"), "Farm %v: invalid listener: %q", f.Name, f.Listener)
+	}
+
+	if net.ParseIP(f.VirtualIP) == nil {
+		errs.add(f.pos.lineOf("virtual_ip"), "Farm %v: invalid virtual IP address: %q", f.Name, f.VirtualIP)
+	}
+
+	if f.VirtualPort < 0 || f.VirtualPort > 65535 {
+		errs.add(f.pos.lineOf("virtual_port"), "Farm %v: invalid virtual port: %v", f.Name, f.VirtualPort)
+	}
+
+	if len(f.Certificates) > 0 && f.Listener != string(zevenetlb.FarmListener_HTTPS) {
+		errs.add(f.pos.lineOf("certificates"), "Farm %v: certificates require the https listener", f.Name)
+	}
+
+	if !isOneOf(f.Ciphers, "", string(zevenetlb.FarmCiphers_All), string(zevenetlb.FarmCiphers_High), string(zevenetlb.FarmCiphers_Custom)) {
+		errs.add(f.pos.lineOf("ciphers"), "Farm %v: invalid ciphers: %q", f.Name, f.Ciphers)
+	}
+
+	if !isOneOf(f.HTTPVerbs, "",
+		string(zevenetlb.FarmHTTPVerb_Standard),
+		string(zevenetlb.FarmHTTPVerb_Extended),
+		string(zevenetlb.FarmHTTPVerb_WebDAV),
+		string(zevenetlb.FarmHTTPVerb_MicrosoftWebDAV),
+		string(zevenetlb.FarmHTTPVerb_MicrosoftRPC)) {
+		errs.add(f.pos.lineOf("http_verbs"), "Farm %v: invalid HTTP verbs: %q", f.Name, f.HTTPVerbs)
+	}
+
+	if !isOneOf(f.RewriteLocation, "",
+		string(zevenetlb.FarmRewriteLocation_Enabled),
+		string(zevenetlb.FarmRewriteLocation_BackendsOnly),
+		string(zevenetlb.FarmRewriteLocation_Disabled)) {
+		errs.add(f.pos.lineOf("rewrite_location"), "Farm %v: invalid rewrite location: %q", f.Name, f.RewriteLocation)
+	}
+
+	for key, value := range map[string]int{
+		"connection_timeout": f.ConnectionTimeout,
+		"request_timeout":    f.RequestTimeout,
+		"response_timeout":   f.ResponseTimeout,
+		"resurrect_interval": f.ResurrectInterval,
+	} {
+		if value < 0 {
+			errs.add(f.pos.lineOf(key), "Farm %v: %v must not be negative: %v", f.Name, key, value)
+		}
+	}
+
+	// services
+	names := map[string]bool{}
+
+	for _, svc := range f.Services {
+		if svc == nil {
+			errs.add(f.pos.lineOf("services"), "Farm %v: empty service", f.Name)
+			continue
+		}
+
+		if svc.Name == "" {
+			errs.add(svc.pos.line, "Farm %v: the service name is required", f.Name)
+		} else if names[svc.Name] {
+			errs.add(svc.pos.lineOf("name"), "Farm %v: duplicate service: %v", f.Name, svc.Name)
+		}
+
+		names[svc.Name] = true
+
+		errs = append(errs, svc.validate(f.Name)...)
+	}
+
+	return errs
+}
+
+func (s *serviceFormat) validate(farmName string) ValidationErrors {
+	var errs ValidationErrors
+
+	prefix := fmt.Sprintf("Service %v/%v", farmName, s.Name)
+
+	if !isOneOf(s.RedirectType,
+		string(zevenetlb.ServiceRedirectType_Disabled),
+		string(zevenetlb.ServiceRedirectType_Default),
+		string(zevenetlb.ServiceRedirectType_Append)) {
+		errs.add(s.pos.lineOf("redirect_type"), "%v: invalid redirect type: %q", prefix, s.RedirectType)
+	}
+
+	if !isOneOf(s.Persistence,
+		string(zevenetlb.ServiceConnPersistenceMode_Disabled),
+		string(zevenetlb.ServiceConnPersistenceMode_IPAddress),
+		string(zevenetlb.ServiceConnPersistenceMode_BasicHeaders),
+		string(zevenetlb.ServiceConnPersistenceMode_Url),
+		string(zevenetlb.ServiceConnPersistenceMode_QueryParameter),
+		string(zevenetlb.ServiceConnPersistenceMode_Cookie),
+		string(zevenetlb.ServiceConnPersistenceMode_Header)) {
+		errs.add(s.pos.lineOf("persistence"), "%v: invalid persistence: %q", prefix, s.Persistence)
+	}
+
+	if s.PersistenceTimeout < 0 {
+		errs.add(s.pos.lineOf("persistence_timeout"), "%v: persistence_timeout must not be negative: %v", prefix, s.PersistenceTimeout)
+	}
+
+	if fg := s.FarmGuardian; fg != nil && fg.Interval < 0 {
+		errs.add(fg.pos.lineOf("interval"), "%v: the farmguardian interval must not be negative: %v", prefix, fg.Interval)
+	}
+
+	// backends
+	endpoints := map[string]bool{}
+
+	for _, b := range s.Backends {
+		if b == nil {
+			errs.add(s.pos.lineOf("backends"), "%v: empty backend", prefix)
+			continue
+		}
+
+		if net.ParseIP(b.IP) == nil {
+			errs.add(b.pos.lineOf("ip"), "%v: invalid backend IP address: %q", prefix, b.IP)
+		}
+
+		if b.Port <= 0 || b.Port > 65535 {
+			errs.add(b.pos.lineOf("port"), "%v: invalid backend port: %v", prefix, b.Port)
+		}
+
+		if b.Weight != nil && *b.Weight < 0 {
+			errs.add(b.pos.lineOf("weight"), "%v: the backend weight must not be negative: %v", prefix, *b.Weight)
+		}
+
+		if b.Timeout != nil && *b.Timeout < 0 {
+			errs.add(b.pos.lineOf("timeout"), "%v: the backend timeout must not be negative: %v", prefix, *b.Timeout)
+		}
+
+		endpoint := net.JoinHostPort(zevenetlb.NormalizeIPAddress(b.IP), strconv.Itoa(b.Port))
+
+		if endpoints[endpoint] {
+			errs.add(b.pos.line, "%v: duplicate backend: %v", prefix, endpoint)
+		}
+
+		endpoints[endpoint] = true
+	}
+
+	return errs
+}
+
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+const sampleConfig = `interfaces:
+  - name: eth0:web
+    ip: 10.0.0.10
+
+certificates:
+  - file: web.pem
+    path: certs/web.pem
+
+farms:
+  - name: web
+    listener: https
+    virtual_ip: 10.0.0.10
+    virtual_port: 443
+    certificates: [web.pem]
+    ciphers: highsecurity
+    disable_sslv3: true
+    disable_tlsv1: false
+    error_503: Service unavailable
+    services:
+      - name: default
+        host_pattern: www.example.com
+        persistence: COOKIE
+        persistence_id: SESSIONID
+        farmguardian:
+          enabled: true
+          logs: false
+          interval: 10
+        backends:
+          - ip: 10.0.1.1
+            port: 8080
+          - ip: 10.0.1.2
+            port: 8080
+            weight: 2
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(sampleConfig), filepath.Join("infra", "lb.yaml"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.VirtualInterfaces) != 1 || config.VirtualInterfaces[0].Name != "eth0:web" {
+		t.Fatalf("Unexpected interfaces: %v", config.VirtualInterfaces)
+	}
+
+	if len(config.Certificates) != 1 || config.Certificates[0].Path != filepath.Join("infra", "certs", "web.pem") {
+		t.Fatalf("Unexpected certificates: %v", config.Certificates)
+	}
+
+	farm := config.GetFarm("web")
+
+	if farm == nil {
+		t.Fatal("Farm not found")
+	}
+
+	if farm.Listener != zevenetlb.FarmListener_HTTPS || farm.VirtualPort != 443 || !farm.DisableSSLv3 || len(farm.Certificates) != 1 || farm.Certificates[0].Filename != "web.pem" {
+		t.Fatalf("Unexpected farm: %v", farm)
+	}
+
+	service := farm.Services[0]
+
+	if service.ConnectionPersistenceMode != zevenetlb.ServiceConnPersistenceMode_Cookie || !service.FarmGuardianEnabled || service.FarmGuardianLogsEnabled != zevenetlb.OptionalBool_False || service.FarmGuardianCheckIntervalSeconds != 10 {
+		t.Fatalf("Unexpected service: %v", service)
+	}
+
+	backends := service.Backends
+
+	if len(backends) != 2 || backends[0].Weight != nil || backends[1].Weight == nil || *backends[1].Weight != 2 || backends[1].FarmName != "web" || backends[1].ServiceName != "default" {
+		t.Fatalf("Unexpected backends: %v", backends)
+	}
+
+	if specs := config.FarmSpecs(); len(specs) != 1 || specs[0].Farm.FarmName != "web" {
+		t.Fatalf("Unexpected specs: %v", specs)
+	}
+
+	// omitted booleans are unset, *false* is set explicitly
+	flags := config.Flags["web"]
+
+	if flags.DisableSSLv3 != zevenetlb.OptionalBool_True || flags.DisableTLSv1 != zevenetlb.OptionalBool_False || flags.DisableSSLv2 != zevenetlb.OptionalBool_Nil {
+		t.Fatalf("Unexpected farm flags: %+v", flags)
+	}
+
+	serviceFlags := flags.Services["default"]
+
+	if serviceFlags.FarmGuardianEnabled != zevenetlb.OptionalBool_True || serviceFlags.EncryptedBackends != zevenetlb.OptionalBool_Nil {
+		t.Fatalf("Unexpected service flags: %+v", serviceFlags)
+	}
+
+	if specs := config.FarmSpecs(); specs[0].Flags.DisableTLSv1 != zevenetlb.OptionalBool_False {
+		t.Fatalf("Unexpected spec flags: %+v", specs[0].Flags)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	config, err := Parse([]byte(`{
+	"farms": [
+		{
+			"name": "web",
+			"virtual_ip": "10.0.0.10",
+			"virtual_port": 80,
+			"services": [
+				{ "name": "default", "backends": [ { "ip": "10.0.1.1", "port": 80 } ] }
+			]
+		}
+	]
+}`), "lb.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Farms) != 1 || len(config.Farms[0].Services[0].Backends) != 1 {
+		t.Fatalf("Unexpected farms: %v", config.Farms)
+	}
+
+	// the listener is kept for existing farms
+	if config.Farms[0].Listener != "" {
+		t.Fatalf("Unexpected listener: %v", config.Farms[0].Listener)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		config   string
+		expected []string
+	}{
+		{
+			config: `farms:
+  - name: web
+    virtual_ip: 10.0.0.300
+    virtual_port: 80
+    certificates: [web.pem]
+    services:
+      - name: default
+        persistence: SESSION
+        backends:
+          - ip: 10.0.1.1
+            port: 0
+          - ip: 10.0.1.1
+            port: 0
+  - name: web
+    virtual_ip: 10.0.0.10
+`,
+			expected: []string{
+				`lb.yaml:3: Farm web: invalid virtual IP address: "10.0.0.300"`,
+				`lb.yaml:5: Farm web: certificates require the https listener`,
+				`lb.yaml:8: Service web/default: invalid persistence: "SESSION"`,
+				`lb.yaml:11: Service web/default: invalid backend port: 0`,
+				`lb.yaml:12: Service web/default: duplicate backend: 10.0.1.1:0`,
+				`lb.yaml:13: Service web/default: invalid backend port: 0`,
+				`lb.yaml:14: Duplicate farm: web`,
+			},
+		},
+		{
+			config: `farms:
+  - name: web
+    virtual_ip: 10.0.0.10
+    virtual_port: http
+    backend: []
+`,
+			expected: []string{
+				"lb.yaml:4: cannot unmarshal !!str `http` into int",
+				`lb.yaml:5: unknown field "backend"`,
+			},
+		},
+		{
+			config: "farms:\n  - name: web\n virtual_ip: 10.0.0.10\n",
+			expected: []string{
+				`lb.yaml:2: did not find expected key`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.config), "lb.yaml")
+
+		errs, ok := err.(ValidationErrors)
+
+		if !ok {
+			t.Fatalf("Expected validation errors, got %v", err)
+		}
+
+		var messages []string
+
+		for _, e := range errs {
+			messages = append(messages, e.Error())
+		}
+
+		if strings.Join(messages, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Unexpected errors:\n%v\nexpected:\n%v", err, strings.Join(test.expected, "\n"))
+		}
+	}
+}
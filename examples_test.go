@@ -1,6 +1,10 @@
 package zevenetlb
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // This is how to connect to the Zevenet loadbalancer.
 func ExampleConnect() {
@@ -22,6 +26,18 @@ func ExampleZapiSession_GetFarm() {
 	fmt.Println(farm)
 }
 
+// This is how to limit the time spent on a call, e.g. to abort a deployment.
+func ExampleZapiSession_GetFarmContext() {
+	session, _ := Connect("myloadbalancer:444", "zapi-key", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	farm, _ := session.GetFarmContext(ctx, "myfarm")
+
+	fmt.Println(farm)
+}
+
 // This is how to create a new HTTP farm *without* SSL support.
 func ExampleZapiSession_CreateFarmAsHTTP() {
 	session, _ := Connect("myloadbalancer:444", "zapi-key", nil)
@@ -32,6 +48,7 @@ func ExampleZapiSession_CreateFarmAsHTTP() {
 }
 
 // This is how to create a new HTTP farm *with* SSL support, using the Zevenet default certificate.
+// Use *UploadCertificate()* to provide your own certificate.
 func ExampleZapiSession_CreateFarmAsHTTPS() {
 	session, _ := Connect("myloadbalancer:444", "zapi-key", nil)
 
@@ -0,0 +1,215 @@
+// Package exporter provides Prometheus metrics on a Zevenet loadbalancer, e.g. the status of farms and
+// backends, the number of connections, and the expiry of certificates.
+//
+// For HTTP and HTTPS farms, the configured backend weights and whether farmguardian is enabled are provided,
+// too. Other settings, e.g. timeouts and the settings of L4xNAT and DATALINK farms, are left out deliberately,
+// as they do not describe the state of the loadbalancer. Backends in maintenance are reported by their status.
+//
+// The metrics are written in the Prometheus text format and do not require the Prometheus client library.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+var farmStatuses = []zevenetlb.FarmStatus{
+	zevenetlb.FarmStatus_Up,
+	zevenetlb.FarmStatus_Down,
+	zevenetlb.FarmStatus_NeedsRestart,
+	zevenetlb.FarmStatus_Critical,
+	zevenetlb.FarmStatus_Problem,
+	zevenetlb.FarmStatus_Maintenance,
+}
+
+var backendStatuses = []zevenetlb.BackendStatus{
+	zevenetlb.BackendStatus_Up,
+	zevenetlb.BackendStatus_Down,
+	zevenetlb.BackendStatus_Maintenance,
+	zevenetlb.BackendStatus_Undefined,
+}
+
+// Exporter scrapes a Zevenet loadbalancer and provides its state as Prometheus metrics.
+type Exporter struct {
+	// Session is used to query the loadbalancer.
+	Session *zevenetlb.ZapiSession
+
+	// Namespace is the prefix of all metric names. Defaults to "zevenet".
+	Namespace string
+
+	// SkipCertificates disables the certificate expiry metrics.
+	SkipCertificates bool
+}
+
+// New creates a new exporter for the session.
+func New(session *zevenetlb.ZapiSession) *Exporter {
+	return &Exporter{
+		Session:   session,
+		Namespace: "zevenet",
+	}
+}
+
+func (e *Exporter) metricName(name string) string {
+	if e.Namespace == "" {
+		return "zevenet_" + name
+	}
+
+	return e.Namespace + "_" + name
+}
+
+// ServeHTTP scrapes the loadbalancer and responds with the metrics.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buffer bytes.Buffer
+
+	err := e.WriteMetrics(r.Context(), &buffer)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buffer.Bytes())
+}
+
+// WriteMetrics scrapes the loadbalancer and writes the metrics in the Prometheus text format.
+// An unreachable loadbalancer is reported using the "up" metric instead of an error.
+func (e *Exporter) WriteMetrics(ctx context.Context, w io.Writer) error {
+	metrics := newMetricSet()
+	started := time.Now()
+
+	e.collect(ctx, metrics)
+
+	metrics.add(e.metricName("scrape_duration_seconds"), "Duration of the scrape of the loadbalancer.", time.Since(started).Seconds())
+
+	return metrics.write(w)
+}
+
+func (e *Exporter) collect(ctx context.Context, metrics *metricSet) {
+	scrapeErrors := 0
+
+	defer func() {
+		metrics.add(e.metricName("scrape_errors"), "Number of failed requests during the scrape.", float64(scrapeErrors))
+	}()
+
+	// is the loadbalancer available?
+	up, _ := e.Session.PingContext(ctx)
+
+	metrics.add(e.metricName("up"), "Whether the loadbalancer is reachable.", boolValue(up))
+
+	if !up {
+		scrapeErrors++
+		return
+	}
+
+	// farms and backends
+	farms, err := e.Session.GetAllFarmsContext(ctx)
+
+	if err != nil {
+		scrapeErrors++
+	}
+
+	for _, f := range farms {
+		e.collectFarm(f, metrics)
+
+		stats, err := e.Session.GetFarmStatsContext(ctx, f.FarmName)
+
+		if err != nil {
+			scrapeErrors++
+			continue
+		}
+
+		e.collectFarmStats(f, stats, metrics)
+
+		// configuration of HTTP farms
+		if f.Profile != zevenetlb.FarmProfile_HTTP && f.Profile != zevenetlb.FarmProfile_HTTPS {
+			continue
+		}
+
+		farm, err := e.Session.GetFarmContext(ctx, f.FarmName)
+
+		if err != nil {
+			scrapeErrors++
+			continue
+		}
+
+		// deleted in the meantime?
+		if farm != nil {
+			e.collectFarmConfig(farm, metrics)
+		}
+	}
+
+	// certificates
+	if !e.SkipCertificates {
+		certs, err := e.Session.GetAllCertificatesContext(ctx)
+
+		if err != nil {
+			scrapeErrors++
+		}
+
+		for _, c := range certs {
+			if c.IsCSR() {
+				continue
+			}
+
+			expiresAt, err := c.ExpirationTime()
+
+			if err != nil {
+				scrapeErrors++
+				continue
+			}
+
+			metrics.add(e.metricName("certificate_expiry_timestamp_seconds"), "Expiration date of the certificate as unix timestamp.", float64(expiresAt.Unix()),
+				"certificate", c.Filename, "common_name", c.CommonName)
+		}
+	}
+}
+
+func (e *Exporter) collectFarm(f zevenetlb.FarmInfo, metrics *metricSet) {
+	metrics.add(e.metricName("farm_up"), "Whether the farm is running.", boolValue(f.Status != zevenetlb.FarmStatus_Down),
+		"farm", f.FarmName, "profile", string(f.Profile))
+
+	for _, s := range farmStatuses {
+		metrics.add(e.metricName("farm_status"), "Status of the farm, 1 for the current status.", boolValue(f.Status == s),
+			"farm", f.FarmName, "status", string(s))
+	}
+}
+
+func (e *Exporter) collectFarmStats(f zevenetlb.FarmInfo, stats *zevenetlb.FarmStats, metrics *metricSet) {
+	for _, b := range stats.Backends {
+		labels := []string{"farm", f.FarmName, "service", b.ServiceName, "backend", strconv.Itoa(b.ID), "address", b.IPAddress, "port", strconv.Itoa(b.Port)}
+
+		metrics.add(e.metricName("backend_up"), "Whether the backend is up.", boolValue(b.Status == zevenetlb.BackendStatus_Up), labels...)
+		metrics.add(e.metricName("backend_established_connections"), "Number of established connections to the backend.", float64(b.Established), labels...)
+		metrics.add(e.metricName("backend_pending_connections"), "Number of pending connections to the backend.", float64(b.Pending), labels...)
+
+		for _, s := range backendStatuses {
+			statusLabels := append(append([]string(nil), labels...), "status", string(s))
+
+			metrics.add(e.metricName("backend_status"), "Status of the backend, 1 for the current status.", boolValue(b.Status == s), statusLabels...)
+		}
+	}
+}
+
+func (e *Exporter) collectFarmConfig(farm *zevenetlb.FarmDetails, metrics *metricSet) {
+	for _, svc := range farm.Services {
+		metrics.add(e.metricName("service_farmguardian_enabled"), "Whether farmguardian checks the backends of the service.", boolValue(svc.FarmGuardianEnabled),
+			"farm", farm.FarmName, "service", svc.ServiceName)
+
+		for _, b := range svc.Backends {
+			// the default weight is not reported by the ZAPI
+			if b.Weight == nil {
+				continue
+			}
+
+			metrics.add(e.metricName("backend_weight"), "Configured weight of the backend.", float64(*b.Weight),
+				"farm", farm.FarmName, "service", svc.ServiceName, "backend", strconv.Itoa(b.ID), "address", b.IPAddress, "port", strconv.Itoa(b.Port))
+		}
+	}
+}
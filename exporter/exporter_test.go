@@ -0,0 +1,108 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+)
+
+var testResponses = map[string]string{
+	"/zapi/v3.1/zapi.cgi/system/version":  `{"description":"System version","params":{"appliance_version":"ZCE 5 (v5.0)"}}`,
+	"/zapi/v3.1/zapi.cgi/farms":           `{"description":"List farms","params":[{"farmname":"web","profile":"http","status":"problem","vip":"10.0.0.1","vport":"80"}]}`,
+	"/zapi/v3.1/zapi.cgi/farms/web":       `{"description":"List farm","params":{"listener":"http","status":"problem","vip":"10.0.0.1","vport":80},"services":[{"id":"srv\"1","fgenabled":"true","backends":[{"id":0,"ip":"10.0.0.10","port":80,"weight":5},{"id":1,"ip":"10.0.0.11","port":80}]}]}`,
+	"/zapi/v3.1/zapi.cgi/stats/farms/web": `{"description":"List farm stats","backends":[{"id":0,"ip":"10.0.0.10","port":80,"service":"srv\"1","established":4,"pending":1,"status":"up"},{"id":1,"ip":"10.0.0.11","port":80,"service":"srv\"1","established":0,"pending":0,"status":"down"}]}`,
+	"/zapi/v3.1/zapi.cgi/certificates":    `{"description":"List certificates","params":[{"CN":"www.example.com","file":"web.pem","type":"Certificate","expiration":"2030-01-01 00:00:00 GMT"},{"file":"web.csr","type":"CSR"}]}`,
+}
+
+func createTestSession(t *testing.T) (*zevenetlb.ZapiSession, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := testResponses[r.URL.Path]
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"description":"Not found","message":"Request not found"}`))
+			return
+		}
+
+		w.Write([]byte(res))
+	}))
+
+	session, err := zevenetlb.Connect(server.URL, "zapi-key", nil)
+
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	return session, server.Close
+}
+
+func TestWriteMetrics(t *testing.T) {
+	session, closeServer := createTestSession(t)
+
+	defer closeServer()
+
+	var buffer bytes.Buffer
+
+	err := New(session).WriteMetrics(context.Background(), &buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := buffer.String()
+
+	expected := []string{
+		"# TYPE zevenet_up gauge\nzevenet_up 1\n",
+		`zevenet_farm_up{farm="web",profile="http"} 1`,
+		`zevenet_farm_status{farm="web",status="problem"} 1`,
+		`zevenet_farm_status{farm="web",status="up"} 0`,
+		`zevenet_backend_up{farm="web",service="srv\"1",backend="0",address="10.0.0.10",port="80"} 1`,
+		`zevenet_backend_established_connections{farm="web",service="srv\"1",backend="0",address="10.0.0.10",port="80"} 4`,
+		`zevenet_backend_status{farm="web",service="srv\"1",backend="1",address="10.0.0.11",port="80",status="down"} 1`,
+		`zevenet_service_farmguardian_enabled{farm="web",service="srv\"1"} 1`,
+		`zevenet_backend_weight{farm="web",service="srv\"1",backend="0",address="10.0.0.10",port="80"} 5`,
+		`zevenet_certificate_expiry_timestamp_seconds{certificate="web.pem",common_name="www.example.com"} 1.893456e+09`,
+		"zevenet_scrape_errors 0\n",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Fatalf("Expected output to contain '%v', but got:\n%v", e, output)
+		}
+	}
+
+	// the default weight is not reported
+	if strings.Contains(output, `zevenet_backend_weight{farm="web",service="srv\"1",backend="1"`) {
+		t.Fatalf("Expected the default weight to be skipped, but got:\n%v", output)
+	}
+
+	if strings.Contains(output, "web.csr") {
+		t.Fatalf("Expected CSRs to be skipped, but got:\n%v", output)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	session, closeServer := createTestSession(t)
+
+	defer closeServer()
+
+	rec := httptest.NewRecorder()
+
+	New(session).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected HTTP status code 200, but got %v", rec.Code)
+	}
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Unexpected content type: %v", rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricFamily contains all samples of a metric in the Prometheus text format.
+type metricFamily struct {
+	Name    string
+	Help    string
+	Samples []metricSample
+}
+
+type metricSample struct {
+	Labels []string
+	Value  float64
+}
+
+// metricSet collects metric families before writing them.
+type metricSet struct {
+	families map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{
+		families: make(map[string]*metricFamily),
+	}
+}
+
+// add appends a sample to a gauge. The *labels* are name/value pairs.
+func (ms *metricSet) add(name string, help string, value float64, labels ...string) {
+	family, ok := ms.families[name]
+
+	if !ok {
+		family = &metricFamily{Name: name, Help: help}
+		ms.families[name] = family
+	}
+
+	family.Samples = append(family.Samples, metricSample{Labels: labels, Value: value})
+}
+
+// write outputs all metric families sorted by name in the Prometheus text format.
+func (ms *metricSet) write(w io.Writer) error {
+	var names []string
+
+	for n := range ms.families {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		family := ms.families[n]
+
+		_, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v gauge\n", family.Name, escapeHelp(family.Help), family.Name)
+
+		if err != nil {
+			return err
+		}
+
+		for _, s := range family.Samples {
+			_, err = fmt.Fprintf(w, "%v%v %v\n", family.Name, formatLabels(s.Labels), formatValue(s.Value))
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) <= 0 {
+		return ""
+	}
+
+	var parts []string
+
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%v="%v"`, labels[i], escapeLabelValue(labels[i+1])))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -1,6 +1,10 @@
 module github.com/konsorten/zevenet-lb-go
 
+go 1.18
+
 require (
 	github.com/sparrc/go-ping v0.0.0-20181106165434-ef3ab45e41b0
-	golang.org/x/net v0.0.0-20181114220301-adae6a3d119a // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/net v0.0.0-20181114220301-adae6a3d119a // indirect
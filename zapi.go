@@ -2,8 +2,11 @@ package zevenetlb
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -21,6 +24,28 @@ var defaultConfigOptions = &ConfigOptions{
 type ConfigOptions struct {
 	APICallTimeout time.Duration
 	ZapiVersion    string
+
+	// TLSRootCAs contains the certificate authorities used to verify the loadbalancer's certificate.
+	// If *nil*, the system's root certificates are used.
+	TLSRootCAs *x509.CertPool
+
+	// TLSPinnedFingerprint is the SHA-256 fingerprint of the loadbalancer's certificate, e.g. "AB:CD:...".
+	// If set, the certificate is trusted if its fingerprint matches, even if it is self-signed.
+	TLSPinnedFingerprint string
+
+	// TLSClientCertificate is presented to the loadbalancer, if set.
+	TLSClientCertificate *tls.Certificate
+
+	// TLSInsecureSkipVerify disables any verification of the loadbalancer's certificate.
+	// Only use this for testing purposes.
+	TLSInsecureSkipVerify bool
+
+	// RetryPolicy defines how failed API calls are retried. If *nil*, failed calls are not retried.
+	RetryPolicy *RetryPolicy
+
+	// WrapTransport is called once while connecting and allows to intercept all HTTP requests,
+	// e.g. to record and replay the traffic using *zevenettest.Recorder*.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 func (opt *ConfigOptions) setDefaults(def *ConfigOptions) {
@@ -38,6 +63,8 @@ type ZapiSession struct {
 	ZapiKey       string
 	Transport     *http.Transport
 	ConfigOptions *ConfigOptions
+
+	roundTripper http.RoundTripper
 }
 
 // String returns the session's hostname.
@@ -53,11 +80,29 @@ type APIRequest struct {
 	ContentType string
 }
 
+var (
+	// ErrNotFound is matched by errors caused by a missing entity, e.g. a farm.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is matched by errors caused by an invalid or missing ZAPI key.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrConflict is matched by errors caused by an entity that already exists or is in use.
+	ErrConflict = errors.New("conflict")
+
+	// ErrValidation is matched by errors caused by invalid parameters.
+	ErrValidation = errors.New("validation failed")
+)
+
 // RequestError contains information about any error we get from a request.
+// Use *errors.Is()* with *ErrNotFound*, *ErrUnauthorized*, *ErrConflict* or *ErrValidation* to check for the type of failure.
 type RequestError struct {
 	Message     string `json:"message,omitempty"`
 	Description string `json:"description,omitempty"`
-	Response    string
+	Response    string `json:"-"`
+	StatusCode  int    `json:"-"`
+	Method      string `json:"-"`
+	URL         string `json:"-"`
 }
 
 // Error returns the error message.
@@ -69,8 +114,30 @@ func (r RequestError) Error() string {
 	return fmt.Sprintf("%v", r.Message)
 }
 
+// Is checks if the error matches one of the sentinel errors, e.g. *ErrNotFound*.
+func (r RequestError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		// older ZAPI versions report missing entities using a generic status code
+		return r.StatusCode == http.StatusNotFound || strings.Contains(r.Message, "not found")
+	case ErrUnauthorized:
+		return r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return r.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return r.StatusCode == http.StatusBadRequest || r.StatusCode == http.StatusUnprocessableEntity
+	}
+
+	return false
+}
+
 // Connect sets up our connection to the Zevenet system.
 func Connect(host, zapiKey string, configOptions *ConfigOptions) (*ZapiSession, error) {
+	return ConnectContext(context.Background(), host, zapiKey, configOptions)
+}
+
+// ConnectContext sets up our connection to the Zevenet system using the provided context.
+func ConnectContext(ctx context.Context, host, zapiKey string, configOptions *ConfigOptions) (*ZapiSession, error) {
 	var url string
 	if !strings.HasPrefix(host, "http") {
 		url = fmt.Sprintf("https://%s", host)
@@ -83,20 +150,28 @@ func Connect(host, zapiKey string, configOptions *ConfigOptions) (*ZapiSession,
 		configOptions.setDefaults(defaultConfigOptions)
 	}
 
+	tlsConfig, err := configOptions.tlsConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
 	// create the session
 	session := &ZapiSession{
 		Host:    url,
 		ZapiKey: zapiKey,
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+			TLSClientConfig: tlsConfig,
 		},
 		ConfigOptions: configOptions,
 	}
 
+	if configOptions.WrapTransport != nil {
+		session.roundTripper = configOptions.WrapTransport(session.Transport)
+	}
+
 	// initialize the session
-	err := session.initialize()
+	err = session.initialize(ctx)
 
 	if err != nil {
 		return nil, err
@@ -106,16 +181,21 @@ func Connect(host, zapiKey string, configOptions *ConfigOptions) (*ZapiSession,
 	return session, nil
 }
 
-func (s *ZapiSession) initialize() (err error) {
+func (s *ZapiSession) initialize(ctx context.Context) (err error) {
 	// test connection
-	_, err = s.GetSystemVersion()
+	_, err = s.GetSystemVersionContext(ctx)
 	return
 }
 
 // Ping checks if the loadbalancer is available.
 func (s *ZapiSession) Ping() (bool, string) {
+	return s.PingContext(context.Background())
+}
+
+// PingContext checks if the loadbalancer is available using the provided context.
+func (s *ZapiSession) PingContext(ctx context.Context) (bool, string) {
 	// test connection
-	_, err := s.GetSystemVersion()
+	_, err := s.GetSystemVersionContext(ctx)
 
 	if err != nil {
 		return false, err.Error()
@@ -125,15 +205,67 @@ func (s *ZapiSession) Ping() (bool, string) {
 }
 
 // apiCall is used to query the ZAPI.
-func (s *ZapiSession) apiCall(options *APIRequest) ([]byte, error) {
-	var req *http.Request
+// Failed calls are retried according to the *RetryPolicy*, if any.
+func (s *ZapiSession) apiCall(ctx context.Context, options *APIRequest) ([]byte, error) {
+	if s.ConfigOptions.RetryPolicy == nil {
+		return s.apiCallOnce(ctx, options)
+	}
+
+	policy := s.ConfigOptions.RetryPolicy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		data, err := s.apiCallOnce(ctx, options)
+
+		if err == nil || !policy.shouldRetry(ctx, options.Method, attempt, err) {
+			return data, err
+		}
+
+		backoff := policy.backoff(attempt)
+
+		if policy.OnRetry != nil {
+			info := RetryAttempt{
+				Attempt: attempt,
+				Method:  strings.ToUpper(options.Method),
+				URL:     s.apiURL(options),
+				Err:     err,
+				Backoff: backoff,
+			}
+
+			var reqErr RequestError
+
+			if errors.As(err, &reqErr) {
+				info.StatusCode = reqErr.StatusCode
+			}
+
+			policy.OnRetry(info)
+		}
+
+		// wait for the next attempt
+		if sleepErr := sleepContext(ctx, backoff); sleepErr != nil {
+			return data, err
+		}
+	}
+}
+
+func (s *ZapiSession) apiURL(options *APIRequest) string {
+	return fmt.Sprintf("%v/zapi/v%v/zapi.cgi/%v", s.Host, s.ConfigOptions.ZapiVersion, options.URL)
+}
+
+// apiCallOnce performs a single request to the ZAPI.
+func (s *ZapiSession) apiCallOnce(ctx context.Context, options *APIRequest) ([]byte, error) {
 	client := &http.Client{
 		Transport: s.Transport,
 		Timeout:   s.ConfigOptions.APICallTimeout,
 	}
-	url := fmt.Sprintf("%v/zapi/v%v/zapi.cgi/%v", s.Host, s.ConfigOptions.ZapiVersion, options.URL)
+	if s.roundTripper != nil {
+		client.Transport = s.roundTripper
+	}
+	url := s.apiURL(options)
 	body := bytes.NewReader([]byte(options.Body))
-	req, _ = http.NewRequest(strings.ToUpper(options.Method), url, body)
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(options.Method), url, body)
+	if err != nil {
+		return nil, err
+	}
 
 	req.Header.Set("ZAPI_KEY", s.ZapiKey)
 
@@ -145,6 +277,10 @@ func (s *ZapiSession) apiCall(options *APIRequest) ([]byte, error) {
 
 	res, err := client.Do(req)
 	if err != nil {
+		if isCertificateError(err) {
+			return nil, CertificateError{Host: s.Host, Err: err}
+		}
+
 		return nil, err
 	}
 
@@ -153,11 +289,7 @@ func (s *ZapiSession) apiCall(options *APIRequest) ([]byte, error) {
 	data, _ := ioutil.ReadAll(res.Body)
 
 	if res.StatusCode >= 400 {
-		if res.Header["Content-Type"][0] == "application/json" {
-			return data, s.checkError(data)
-		}
-
-		return data, fmt.Errorf("HTTP %d :: %s", res.StatusCode, string(data[:]))
+		return data, s.checkError(res, data)
 	}
 
 	// fmt.Println("Resp --", res.StatusCode, " -- ", string(data))
@@ -175,18 +307,18 @@ func (s *ZapiSession) iControlPath(parts []string) string {
 	return buffer.String()
 }
 
-//Generic delete
-func (s *ZapiSession) delete(path ...string) error {
+// Generic delete
+func (s *ZapiSession) delete(ctx context.Context, path ...string) error {
 	req := &APIRequest{
 		Method: "delete",
 		URL:    s.iControlPath(path),
 	}
 
-	_, callErr := s.apiCall(req)
+	_, callErr := s.apiCall(ctx, req)
 	return callErr
 }
 
-func (s *ZapiSession) post(body interface{}, path ...string) error {
+func (s *ZapiSession) post(ctx context.Context, body interface{}, path ...string) error {
 	marshalJSON, err := jsonMarshal(body)
 	if err != nil {
 		return err
@@ -199,11 +331,11 @@ func (s *ZapiSession) post(body interface{}, path ...string) error {
 		ContentType: "application/json",
 	}
 
-	_, callErr := s.apiCall(req)
+	_, callErr := s.apiCall(ctx, req)
 	return callErr
 }
 
-func (s *ZapiSession) put(body interface{}, path ...string) error {
+func (s *ZapiSession) put(ctx context.Context, body interface{}, path ...string) error {
 	marshalJSON, err := jsonMarshal(body)
 	if err != nil {
 		return err
@@ -216,21 +348,44 @@ func (s *ZapiSession) put(body interface{}, path ...string) error {
 		ContentType: "application/json",
 	}
 
-	_, callErr := s.apiCall(req)
+	_, callErr := s.apiCall(ctx, req)
+	return callErr
+}
+
+// postRaw sends a non-JSON body, e.g. a PEM file.
+func (s *ZapiSession) postRaw(ctx context.Context, contentType string, body []byte, path ...string) error {
+	req := &APIRequest{
+		Method:      "post",
+		URL:         s.iControlPath(path),
+		Body:        string(body),
+		ContentType: contentType,
+	}
+
+	_, callErr := s.apiCall(ctx, req)
 	return callErr
 }
 
-//Get a url and populate an entity. If the entity does not exist (404) then the
-//passed entity will be untouched and false will be returned as the second parameter.
-//You can use this to distinguish between a missing entity or an actual error.
-func (s *ZapiSession) getForEntity(e interface{}, path ...string) error {
+// getRaw retrieves a non-JSON response, e.g. a PEM file.
+func (s *ZapiSession) getRaw(ctx context.Context, path ...string) ([]byte, error) {
+	req := &APIRequest{
+		Method: "get",
+		URL:    s.iControlPath(path),
+	}
+
+	return s.apiCall(ctx, req)
+}
+
+// Get a url and populate an entity. If the entity does not exist (404) then the
+// passed entity will be untouched and false will be returned as the second parameter.
+// You can use this to distinguish between a missing entity or an actual error.
+func (s *ZapiSession) getForEntity(ctx context.Context, e interface{}, path ...string) error {
 	req := &APIRequest{
 		Method:      "get",
 		URL:         s.iControlPath(path),
 		ContentType: "application/json",
 	}
 
-	resp, err := s.apiCall(req)
+	resp, err := s.apiCall(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -243,21 +398,25 @@ func (s *ZapiSession) getForEntity(e interface{}, path ...string) error {
 	return nil
 }
 
-// checkError handles any errors we get from our API requests. It returns either the
-// message of the error, if any, or nil.
-func (s *ZapiSession) checkError(resp []byte) error {
-	if len(resp) == 0 {
-		return nil
+// checkError converts an erroneous response into a *RequestError*, using the
+// message returned by the ZAPI, if any.
+func (s *ZapiSession) checkError(res *http.Response, resp []byte) error {
+	reqError := RequestError{
+		Response:   string(resp),
+		StatusCode: res.StatusCode,
+		Method:     res.Request.Method,
+		URL:        res.Request.URL.String(),
 	}
 
-	var reqError RequestError
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") && len(resp) > 0 {
+		err := json.Unmarshal(resp, &reqError)
 
-	err := json.Unmarshal(resp, &reqError)
-	if err != nil {
-		return fmt.Errorf("%s\n%s", err.Error(), string(resp[:]))
+		if err == nil && reqError.Message != "" {
+			return reqError
+		}
 	}
 
-	reqError.Response = string(resp)
+	reqError.Message = fmt.Sprintf("HTTP %d :: %s", res.StatusCode, string(resp[:]))
 
 	return reqError
 }
@@ -0,0 +1,107 @@
+package zevenetlb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"strconv"
+)
+
+//
+// IP Addresses
+//
+
+// NormalizeIPAddress returns the canonical notation of an IP address, e.g. "2001:db8::1" for "2001:0db8:0:0:0:0:0:1".
+// IPv4-mapped IPv6 addresses are converted to IPv4. Invalid addresses are returned unchanged.
+func NormalizeIPAddress(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+
+	if err != nil {
+		return ip
+	}
+
+	return addr.Unmap().String()
+}
+
+// sameIPAddress checks if both strings are the same IP address, regardless of the notation.
+func sameIPAddress(a string, b string) bool {
+	return a == b || NormalizeIPAddress(a) == NormalizeIPAddress(b)
+}
+
+// parseIPAddress parses an IP address returned by the ZAPI.
+func parseIPAddress(ip string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(ip)
+
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("Invalid IP address %q: %w", ip, err)
+	}
+
+	return addr.Unmap(), nil
+}
+
+// parseIPPrefix parses the IP address and netmask of an interface. The netmask is either
+// an IPv4 netmask, e.g. "255.255.255.0", or a prefix length, e.g. "64".
+func parseIPPrefix(ip string, netmask string) (netip.Prefix, error) {
+	addr, err := parseIPAddress(ip)
+
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	bits, err := strconv.Atoi(netmask)
+
+	if err != nil {
+		bits, err = ipv4NetmaskBits(netmask)
+
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+	}
+
+	prefix := netip.PrefixFrom(addr, bits)
+
+	if !prefix.IsValid() {
+		return netip.Prefix{}, fmt.Errorf("Invalid prefix length %v for %v", bits, addr)
+	}
+
+	return prefix, nil
+}
+
+// ipv4NetmaskBits returns the prefix length of an IPv4 netmask, e.g. 24 for "255.255.255.0".
+func ipv4NetmaskBits(netmask string) (int, error) {
+	mask, err := netip.ParseAddr(netmask)
+
+	if err != nil || !mask.Is4() {
+		return 0, fmt.Errorf("Invalid netmask %q", netmask)
+	}
+
+	b := mask.As4()
+	m := binary.BigEndian.Uint32(b[:])
+	ones := bits.LeadingZeros32(^m)
+
+	// the remaining bits have to be zero
+	if m != ^uint32(0)<<uint(32-ones) {
+		return 0, fmt.Errorf("Invalid netmask %q", netmask)
+	}
+
+	return ones, nil
+}
+
+// ipv6Config returns the IP address, netmask, and gateway of an IPv6 interface as expected by the ZAPI,
+// which uses the prefix length as netmask. The *gateway* is optional and can be the zero value.
+func ipv6Config(prefix netip.Prefix, gateway netip.Addr) (string, string, string, error) {
+	if !prefix.IsValid() || !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return "", "", "", fmt.Errorf("Invalid IPv6 address %v: %w", prefix, ErrValidation)
+	}
+
+	if !gateway.IsValid() {
+		return prefix.Addr().String(), strconv.Itoa(prefix.Bits()), "", nil
+	}
+
+	if !gateway.Is6() || gateway.Is4In6() {
+		return "", "", "", fmt.Errorf("Invalid IPv6 gateway %v: %w", gateway, ErrValidation)
+	}
+
+	return prefix.Addr().String(), strconv.Itoa(prefix.Bits()), gateway.String(), nil
+}
@@ -0,0 +1,124 @@
+package zevenetlb
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestNormalizeIPAddress(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1":              "10.0.0.1",
+		"2001:db8::1":           "2001:db8::1",
+		"2001:0db8:0:0:0:0:0:1": "2001:db8::1",
+		"2001:DB8:0000::0001":   "2001:db8::1",
+		"::ffff:10.0.0.1":       "10.0.0.1",
+		"myhost.example.com":    "myhost.example.com",
+		"":                      "",
+	}
+
+	for ip, expected := range tests {
+		if actual := NormalizeIPAddress(ip); actual != expected {
+			t.Errorf("NormalizeIPAddress(%q) = %q, expected %q", ip, actual, expected)
+		}
+	}
+}
+
+func TestParseIPPrefix(t *testing.T) {
+	tests := []struct {
+		ip       string
+		netmask  string
+		expected string
+	}{
+		{"10.0.0.10", "255.255.255.0", "10.0.0.10/24"},
+		{"10.0.0.10", "255.255.255.255", "10.0.0.10/32"},
+		{"10.0.0.10", "0.0.0.0", "10.0.0.10/0"},
+		{"10.0.0.10", "16", "10.0.0.10/16"},
+		{"2001:db8::10", "64", "2001:db8::10/64"},
+		{"10.0.0.10", "255.0.255.0", ""},
+		{"10.0.0.10", "33", ""},
+		{"invalid", "24", ""},
+	}
+
+	for _, test := range tests {
+		prefix, err := parseIPPrefix(test.ip, test.netmask)
+
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("Expected %v/%v to be invalid, got %v", test.ip, test.netmask, prefix)
+			}
+
+			continue
+		}
+
+		if err != nil || prefix.String() != test.expected {
+			t.Errorf("Unexpected prefix of %v/%v: %v (%v)", test.ip, test.netmask, prefix, err)
+		}
+	}
+}
+
+func TestGetBackendByAddressIPv6(t *testing.T) {
+	service := &ServiceDetails{
+		Backends: []BackendDetails{
+			{ID: 0, IPAddress: "10.0.0.1", Port: 80},
+			{ID: 1, IPAddress: "2001:db8::1", Port: 80},
+		},
+	}
+
+	backend, err := service.GetBackendByAddress("2001:0db8:0:0:0:0:0:1", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backend == nil || backend.ID != 1 {
+		t.Fatalf("Unexpected backend: %v", backend)
+	}
+
+	addrPort, err := backend.AddrPort()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addrPort != netip.MustParseAddrPort("[2001:db8::1]:80") {
+		t.Fatalf("Unexpected address: %v", addrPort)
+	}
+}
+
+func TestReconcileIPv6(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:  "ipv6farm",
+			VirtualIP: "10.209.0.30",
+			Services: []ServiceDetails{
+				{
+					ServiceName: "default",
+					Backends: []BackendDetails{
+						{IPAddress: "2001:db8::1", Port: 80},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the expanded notation is the same backend
+	spec.Farm.Services[0].Backends[0].IPAddress = "2001:0db8:0:0:0:0:0:1"
+
+	plan, err := session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Operations) != 0 {
+		t.Fatalf("Expected no changes:\n%v", plan)
+	}
+}
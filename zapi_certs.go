@@ -1,6 +1,17 @@
 package zevenetlb
 
-import "fmt"
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
 
 type certListResponse struct {
 	Description string               `json:"description"`
@@ -25,9 +36,14 @@ func (sv CertificateDetails) String() string {
 
 // GetAllCertificates returns list of all available certificates and CSRs.
 func (s *ZapiSession) GetAllCertificates() ([]CertificateDetails, error) {
+	return s.GetAllCertificatesContext(context.Background())
+}
+
+// GetAllCertificatesContext returns list of all available certificates and CSRs using the provided context.
+func (s *ZapiSession) GetAllCertificatesContext(ctx context.Context) ([]CertificateDetails, error) {
 	var result *certListResponse
 
-	err := s.getForEntity(&result, "certificates")
+	err := s.getForEntity(ctx, &result, "certificates")
 
 	if err != nil {
 		return nil, err
@@ -35,3 +51,245 @@ func (s *ZapiSession) GetAllCertificates() ([]CertificateDetails, error) {
 
 	return result.Params, nil
 }
+
+// GetCertificate returns details on a specific certificate or CSR, or returns *nil* if not found.
+func (s *ZapiSession) GetCertificate(filename string) (*CertificateDetails, error) {
+	return s.GetCertificateContext(context.Background(), filename)
+}
+
+// GetCertificateContext returns details on a specific certificate or CSR using the provided context.
+func (s *ZapiSession) GetCertificateContext(ctx context.Context, filename string) (*CertificateDetails, error) {
+	certs, err := s.GetAllCertificatesContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range certs {
+		if c.Filename == filename {
+			return &c, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// UploadCertificatePEM uploads a PEM bundle, containing the certificate, its private key, and the intermediate certificates.
+// The *filename* has to end with ".pem".
+func (s *ZapiSession) UploadCertificatePEM(filename string, pemData []byte) (*CertificateDetails, error) {
+	return s.UploadCertificatePEMContext(context.Background(), filename, pemData)
+}
+
+// UploadCertificatePEMContext uploads a PEM bundle using the provided context.
+func (s *ZapiSession) UploadCertificatePEMContext(ctx context.Context, filename string, pemData []byte) (*CertificateDetails, error) {
+	// validate the bundle
+	block, _ := pem.Decode(pemData)
+
+	if block == nil {
+		return nil, fmt.Errorf("No PEM data found for certificate %v", filename)
+	}
+
+	err := s.postRaw(ctx, "application/x-pem-file", pemData, "certificates", filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetCertificateContext(ctx, filename)
+}
+
+// UploadCertificate uploads a certificate chain and its private key, e.g. as loaded by *tls.LoadX509KeyPair()*.
+// The *filename* has to end with ".pem".
+func (s *ZapiSession) UploadCertificate(filename string, cert *tls.Certificate) (*CertificateDetails, error) {
+	return s.UploadCertificateContext(context.Background(), filename, cert)
+}
+
+// UploadCertificateContext uploads a certificate chain and its private key using the provided context.
+func (s *ZapiSession) UploadCertificateContext(ctx context.Context, filename string, cert *tls.Certificate) (*CertificateDetails, error) {
+	pemData, err := encodeCertificatePEM(cert.Certificate, cert.PrivateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UploadCertificatePEMContext(ctx, filename, pemData)
+}
+
+// UploadX509Certificate uploads a certificate chain, starting with the leaf certificate, and its private key.
+// The *filename* has to end with ".pem".
+func (s *ZapiSession) UploadX509Certificate(filename string, chain []*x509.Certificate, privateKey crypto.PrivateKey) (*CertificateDetails, error) {
+	return s.UploadX509CertificateContext(context.Background(), filename, chain, privateKey)
+}
+
+// UploadX509CertificateContext uploads a certificate chain and its private key using the provided context.
+func (s *ZapiSession) UploadX509CertificateContext(ctx context.Context, filename string, chain []*x509.Certificate, privateKey crypto.PrivateKey) (*CertificateDetails, error) {
+	var raw [][]byte
+
+	for _, c := range chain {
+		raw = append(raw, c.Raw)
+	}
+
+	pemData, err := encodeCertificatePEM(raw, privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UploadCertificatePEMContext(ctx, filename, pemData)
+}
+
+// encodeCertificatePEM builds a PEM bundle from DER encoded certificates and a private key.
+func encodeCertificatePEM(chain [][]byte, privateKey crypto.PrivateKey) ([]byte, error) {
+	if len(chain) <= 0 {
+		return nil, errors.New("No certificate provided")
+	}
+
+	var buffer bytes.Buffer
+
+	for _, c := range chain {
+		err := pem.Encode(&buffer, &pem.Block{Type: "CERTIFICATE", Bytes: c})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keyBlock *pem.Block
+
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		keyBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keyBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	case nil:
+		return nil, errors.New("No private key provided")
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keyBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	err := pem.Encode(&buffer, keyBlock)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// DownloadCertificate returns the contents of a certificate or CSR file, usually in PEM format.
+func (s *ZapiSession) DownloadCertificate(filename string) ([]byte, error) {
+	return s.DownloadCertificateContext(context.Background(), filename)
+}
+
+// DownloadCertificateContext returns the contents of a certificate or CSR file using the provided context.
+func (s *ZapiSession) DownloadCertificateContext(ctx context.Context, filename string) ([]byte, error) {
+	return s.getRaw(ctx, "certificates", filename)
+}
+
+// DownloadX509Certificates downloads a certificate file and returns the certificates it contains.
+// Any other PEM blocks, like private keys, are skipped.
+func (s *ZapiSession) DownloadX509Certificates(filename string) ([]*x509.Certificate, error) {
+	return s.DownloadX509CertificatesContext(context.Background(), filename)
+}
+
+// DownloadX509CertificatesContext downloads a certificate file and returns the certificates it contains using the provided context.
+func (s *ZapiSession) DownloadX509CertificatesContext(ctx context.Context, filename string) ([]*x509.Certificate, error) {
+	data, err := s.DownloadCertificateContext(ctx, filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+// DeleteCertificate will delete an existing certificate or CSR (or do nothing if missing)
+func (s *ZapiSession) DeleteCertificate(filename string) (bool, error) {
+	return s.DeleteCertificateContext(context.Background(), filename)
+}
+
+// DeleteCertificateContext will delete an existing certificate or CSR (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteCertificateContext(ctx context.Context, filename string) (bool, error) {
+	// retrieve certificate details
+	cert, err := s.GetCertificateContext(ctx, filename)
+
+	if err != nil {
+		return false, err
+	}
+
+	// certificate does not exist?
+	if cert == nil {
+		return false, nil
+	}
+
+	// delete the certificate
+	return true, s.delete(ctx, "certificates", filename)
+}
+
+// CertificateSigningRequest contains the subject fields of a new CSR.
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#create-csr
+type CertificateSigningRequest struct {
+	Name         string `json:"name"`
+	FQDN         string `json:"fqdn"`
+	Division     string `json:"division"`
+	Organization string `json:"organization"`
+	Locality     string `json:"locality"`
+	State        string `json:"state"`
+	Country      string `json:"country"`
+	Email        string `json:"mail"`
+}
+
+// CreateCSR generates a new private key and certificate signing request on the loadbalancer.
+// The CSR is stored as "<name>.csr" and can be retrieved using *DownloadCertificate()*.
+func (s *ZapiSession) CreateCSR(csr *CertificateSigningRequest) (*CertificateDetails, error) {
+	return s.CreateCSRContext(context.Background(), csr)
+}
+
+// CreateCSRContext generates a new private key and certificate signing request using the provided context.
+func (s *ZapiSession) CreateCSRContext(ctx context.Context, csr *CertificateSigningRequest) (*CertificateDetails, error) {
+	err := s.post(ctx, csr, "certificates")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetCertificateContext(ctx, csr.Name+".csr")
+}
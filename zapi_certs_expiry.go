@@ -0,0 +1,248 @@
+package zevenetlb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// certificateDateLayouts contains the date formats used by the ZAPI, depending on the version.
+var certificateDateLayouts = []string{
+	"Jan _2 15:04:05 2006 MST",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+func parseCertificateDate(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+
+	for _, layout := range certificateDateLayouts {
+		t, err := time.Parse(layout, value)
+
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Unknown certificate date format: %v", value)
+}
+
+// IsCSR checks if the entry is a certificate signing request instead of a certificate.
+func (sv CertificateDetails) IsCSR() bool {
+	return strings.EqualFold(sv.Type, "CSR")
+}
+
+// CreationTime returns the parsed *CreationDate* of the certificate.
+func (sv CertificateDetails) CreationTime() (time.Time, error) {
+	return parseCertificateDate(sv.CreationDate)
+}
+
+// ExpirationTime returns the parsed *ExpirationDate* of the certificate.
+func (sv CertificateDetails) ExpirationTime() (time.Time, error) {
+	return parseCertificateDate(sv.ExpirationDate)
+}
+
+// CertificateExpirySeverity is an enumeration of possible selections of *Severity* values.
+type CertificateExpirySeverity string
+
+const (
+	// CertificateExpirySeverity_OK means the certificate does not expire within the window.
+	CertificateExpirySeverity_OK CertificateExpirySeverity = "ok"
+
+	// CertificateExpirySeverity_Warning means the certificate expires within the window.
+	CertificateExpirySeverity_Warning CertificateExpirySeverity = "warning"
+
+	// CertificateExpirySeverity_Critical means the certificate expires within the critical window.
+	CertificateExpirySeverity_Critical CertificateExpirySeverity = "critical"
+
+	// CertificateExpirySeverity_Expired means the certificate has already expired.
+	CertificateExpirySeverity_Expired CertificateExpirySeverity = "expired"
+)
+
+var certificateExpirySeverityRank = map[CertificateExpirySeverity]int{
+	CertificateExpirySeverity_OK:       0,
+	CertificateExpirySeverity_Warning:  1,
+	CertificateExpirySeverity_Critical: 2,
+	CertificateExpirySeverity_Expired:  3,
+}
+
+// CertificateExpiryOptions contains the settings of a certificate expiry report.
+type CertificateExpiryOptions struct {
+	// Window lists all certificates expiring within this duration. Defaults to 30 days.
+	Window time.Duration
+
+	// CriticalWindow marks all certificates expiring within this duration as critical. Defaults to 7 days.
+	CriticalWindow time.Duration
+}
+
+func (opt *CertificateExpiryOptions) withDefaults() CertificateExpiryOptions {
+	res := CertificateExpiryOptions{
+		Window:         30 * 24 * time.Hour,
+		CriticalWindow: 7 * 24 * time.Hour,
+	}
+
+	if opt != nil {
+		if opt.Window > 0 {
+			res.Window = opt.Window
+		}
+		if opt.CriticalWindow > 0 {
+			res.CriticalWindow = opt.CriticalWindow
+		}
+	}
+
+	return res
+}
+
+func (opt CertificateExpiryOptions) severity(remaining time.Duration) CertificateExpirySeverity {
+	switch {
+	case remaining <= 0:
+		return CertificateExpirySeverity_Expired
+	case remaining <= opt.CriticalWindow:
+		return CertificateExpirySeverity_Critical
+	case remaining <= opt.Window:
+		return CertificateExpirySeverity_Warning
+	}
+
+	return CertificateExpirySeverity_OK
+}
+
+// CertificateExpiry contains information on a certificate expiring soon.
+type CertificateExpiry struct {
+	Certificate CertificateDetails
+	ExpiresAt   time.Time
+	Remaining   time.Duration
+	Severity    CertificateExpirySeverity
+	Farms       []string
+}
+
+// String returns the certificate's filename, severity and expiration date.
+func (ce CertificateExpiry) String() string {
+	return fmt.Sprintf("%v (%v, expires %v)", ce.Certificate.Filename, ce.Severity, ce.ExpiresAt.Format(time.RFC3339))
+}
+
+// CertificateExpiryReport lists the certificates expiring within a window, sorted by expiration date.
+type CertificateExpiryReport struct {
+	GeneratedAt  time.Time
+	Certificates []CertificateExpiry
+
+	// Unparseable contains the certificates with an expiration date in an unknown format, which are skipped.
+	Unparseable []CertificateDetails
+}
+
+// Severity returns the highest severity of all listed certificates.
+func (r *CertificateExpiryReport) Severity() CertificateExpirySeverity {
+	res := CertificateExpirySeverity_OK
+
+	for _, c := range r.Certificates {
+		if certificateExpirySeverityRank[c.Severity] > certificateExpirySeverityRank[res] {
+			res = c.Severity
+		}
+	}
+
+	return res
+}
+
+// GetCertificateExpiryReport lists all certificates expiring within the window, including the farms using them.
+// Certificates with an expiration date in an unknown format are listed in *Unparseable* instead of failing the report.
+// The *options* are optional and can be *nil*, using the defaults.
+func (s *ZapiSession) GetCertificateExpiryReport(options *CertificateExpiryOptions) (*CertificateExpiryReport, error) {
+	return s.GetCertificateExpiryReportContext(context.Background(), options)
+}
+
+// GetCertificateExpiryReportContext lists all certificates expiring within the window using the provided context.
+func (s *ZapiSession) GetCertificateExpiryReportContext(ctx context.Context, options *CertificateExpiryOptions) (*CertificateExpiryReport, error) {
+	certs, err := s.GetAllCertificatesContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.getCertificateUsage(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCertificateExpiryReport(time.Now(), certs, usage, options.withDefaults()), nil
+}
+
+// getCertificateUsage returns the names of the farms using a certificate, indexed by the certificate's filename.
+func (s *ZapiSession) getCertificateUsage(ctx context.Context) (map[string][]string, error) {
+	farms, err := s.GetAllFarmsContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string][]string)
+
+	for _, f := range farms {
+		if f.Profile != FarmProfile_HTTP && f.Profile != FarmProfile_HTTPS {
+			continue
+		}
+
+		farm, err := s.GetFarmContext(ctx, f.FarmName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if farm == nil {
+			continue
+		}
+
+		for _, c := range farm.Certificates {
+			usage[c.Filename] = append(usage[c.Filename], farm.FarmName)
+		}
+	}
+
+	return usage, nil
+}
+
+func buildCertificateExpiryReport(now time.Time, certs []CertificateDetails, usage map[string][]string, options CertificateExpiryOptions) *CertificateExpiryReport {
+	report := &CertificateExpiryReport{
+		GeneratedAt: now,
+	}
+
+	for _, c := range certs {
+		if c.IsCSR() {
+			continue
+		}
+
+		expiresAt, err := c.ExpirationTime()
+
+		if err != nil {
+			report.Unparseable = append(report.Unparseable, c)
+			continue
+		}
+
+		remaining := expiresAt.Sub(now)
+		severity := options.severity(remaining)
+
+		if severity == CertificateExpirySeverity_OK {
+			continue
+		}
+
+		farms := append([]string(nil), usage[c.Filename]...)
+
+		sort.Strings(farms)
+
+		report.Certificates = append(report.Certificates, CertificateExpiry{
+			Certificate: c,
+			ExpiresAt:   expiresAt,
+			Remaining:   remaining,
+			Severity:    severity,
+			Farms:       farms,
+		})
+	}
+
+	sort.SliceStable(report.Certificates, func(i, j int) bool {
+		return report.Certificates[i].ExpiresAt.Before(report.Certificates[j].ExpiresAt)
+	})
+
+	return report
+}
@@ -0,0 +1,95 @@
+package zevenetlb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCertificateDate(t *testing.T) {
+	expected := time.Date(2017, time.September, 2, 14, 38, 12, 0, time.UTC)
+
+	for _, value := range []string{"Sep  2 14:38:12 2017 GMT", "Sep 2 14:38:12 2017 GMT", "2017-09-02 14:38:12 GMT", "2017-09-02T14:38:12Z"} {
+		res, err := parseCertificateDate(value)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !res.Equal(expected) {
+			t.Fatalf("Expected %v for '%v', but got %v", expected, value, res)
+		}
+	}
+
+	_, err := parseCertificateDate("")
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+}
+
+func TestBuildCertificateExpiryReport(t *testing.T) {
+	now := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	certs := []CertificateDetails{
+		{Filename: "ok.pem", Type: "Certificate", ExpirationDate: "2019-01-01 00:00:00 GMT"},
+		{Filename: "warning.pem", Type: "Certificate", ExpirationDate: "2018-01-20 00:00:00 GMT"},
+		{Filename: "critical.pem", Type: "Certificate", ExpirationDate: "2018-01-03 00:00:00 GMT"},
+		{Filename: "expired.pem", Type: "Certificate", ExpirationDate: "2017-12-01 00:00:00 GMT"},
+		{Filename: "request.csr", Type: "CSR"},
+		{Filename: "unknown.pem", Type: "Certificate", ExpirationDate: "next year"},
+	}
+
+	usage := map[string][]string{
+		"critical.pem": {"farm2", "farm1"},
+	}
+
+	report := buildCertificateExpiryReport(now, certs, usage, (*CertificateExpiryOptions)(nil).withDefaults())
+
+	expected := []struct {
+		filename string
+		severity CertificateExpirySeverity
+	}{
+		{"expired.pem", CertificateExpirySeverity_Expired},
+		{"critical.pem", CertificateExpirySeverity_Critical},
+		{"warning.pem", CertificateExpirySeverity_Warning},
+	}
+
+	if len(report.Certificates) != len(expected) {
+		t.Fatalf("Expected %v certificates, but got %v", len(expected), report.Certificates)
+	}
+
+	for i, e := range expected {
+		c := report.Certificates[i]
+
+		if c.Certificate.Filename != e.filename || c.Severity != e.severity {
+			t.Fatalf("Expected %v (%v), but got %v", e.filename, e.severity, c)
+		}
+	}
+
+	if farms := report.Certificates[1].Farms; len(farms) != 2 || farms[0] != "farm1" {
+		t.Fatalf("Unexpected farms: %v", farms)
+	}
+
+	// the unparseable certificate does not fail the report
+	if len(report.Unparseable) != 1 || report.Unparseable[0].Filename != "unknown.pem" {
+		t.Fatalf("Unexpected unparseable certificates: %v", report.Unparseable)
+	}
+
+	if report.Severity() != CertificateExpirySeverity_Expired {
+		t.Fatalf("Unexpected report severity: %v", report.Severity())
+	}
+}
+
+func TestGetCertificateExpiryReport(t *testing.T) {
+	session := createTestSession(t)
+
+	report, err := session.GetCertificateExpiryReport(&CertificateExpiryOptions{Window: 365 * 24 * time.Hour})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range report.Certificates {
+		t.Logf("Certificate: %v, Farms: %v", c, c.Farms)
+	}
+}
@@ -1,9 +1,52 @@
 package zevenetlb
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
+	"time"
 )
 
+const (
+	unitTestCertificateName = "unittestgo.pem"
+	unitTestCSRName         = "unittestgo"
+)
+
+func createTestCertificate(t *testing.T, commonName string, notAfter time.Time) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
 func TestGetAllCertificates(t *testing.T) {
 	session := createTestSession(t)
 
@@ -23,3 +66,111 @@ func TestGetAllCertificates(t *testing.T) {
 		t.Logf("Certificate: %v", c)
 	}
 }
+
+func TestEncodeCertificatePEM(t *testing.T) {
+	cert, key := createTestCertificate(t, "unittest.example.com", time.Now().Add(24*time.Hour))
+
+	pemData, err := encodeCertificatePEM([][]byte{cert.Raw}, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bundle has to be a valid key pair
+	_, err = tls.X509KeyPair(pemData, pemData)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a private key is required
+	_, err = encodeCertificatePEM([][]byte{cert.Raw}, nil)
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+}
+
+func TestRoundtripCertificate(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the certificate does not exist
+	_, err := session.DeleteCertificate(unitTestCertificateName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload a new certificate
+	cert, key := createTestCertificate(t, "unittest.example.com", time.Now().Add(24*time.Hour))
+
+	details, err := session.UploadX509Certificate(unitTestCertificateName, []*x509.Certificate{cert}, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteCertificate(unitTestCertificateName)
+
+	if details == nil {
+		t.Fatal("Certificate not found after upload")
+	}
+
+	t.Logf("Certificate: %v", details)
+
+	// download the certificate
+	certs, err := session.DownloadX509Certificates(unitTestCertificateName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(certs) <= 0 || !certs[0].Equal(cert) {
+		t.Fatal("Downloaded certificate does not match the uploaded one")
+	}
+
+	// done, delete the certificate
+	deleted, err := session.DeleteCertificate(unitTestCertificateName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the certificate to succeed, but failed")
+	}
+}
+
+func TestCreateCSR(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the CSR does not exist
+	_, err := session.DeleteCertificate(unitTestCSRName + ".csr")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csr, err := session.CreateCSR(&CertificateSigningRequest{
+		Name:         unitTestCSRName,
+		FQDN:         "unittest.example.com",
+		Division:     "IT",
+		Organization: "Example",
+		Locality:     "Berlin",
+		State:        "Berlin",
+		Country:      "DE",
+		Email:        "unittest@example.com",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if csr == nil {
+		t.Fatal("CSR not found after creation")
+	}
+
+	defer session.DeleteCertificate(csr.Filename)
+
+	t.Logf("CSR: %v", csr)
+}
@@ -0,0 +1,194 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//
+// Cluster
+//
+
+// ClusterRole defines the role of a node in a cluster.
+type ClusterRole string
+
+const (
+	// ClusterRole_Master is the active node, which serves the farms.
+	ClusterRole_Master ClusterRole = "master"
+
+	// ClusterRole_Backup is the passive node, which takes over if the master fails.
+	ClusterRole_Backup ClusterRole = "backup"
+
+	// ClusterRole_Maintenance is a node which has been taken out of the cluster.
+	ClusterRole_Maintenance ClusterRole = "maintenance"
+)
+
+// ClusterNodeStatus defines the health of a node in a cluster.
+type ClusterNodeStatus string
+
+const (
+	// ClusterNodeStatus_Ok is a node which is online and synchronized.
+	ClusterNodeStatus_Ok ClusterNodeStatus = "ok"
+
+	// ClusterNodeStatus_Failure is a node which is online, but failed to synchronize, see its message.
+	ClusterNodeStatus_Failure ClusterNodeStatus = "failure"
+
+	// ClusterNodeStatus_Unreachable is a node which cannot be reached by the other node.
+	ClusterNodeStatus_Unreachable ClusterNodeStatus = "unreachable"
+)
+
+type clusterDetailsResponse struct {
+	Description string         `json:"description"`
+	Params      ClusterDetails `json:"params"`
+}
+
+// ClusterDetails contains the configuration of a cluster. Only available in the Enterprise Edition.
+// See https://www.zevenet.com/zapidoc_ee_v3.1/#cluster
+type ClusterDetails struct {
+	CheckInterval int                 `json:"check_interval"`
+	Failback      string              `json:"failback"`
+	Interface     string              `json:"interface"`
+	Nodes         []ClusterNodeConfig `json:"nodes"`
+}
+
+// ClusterNodeConfig contains the configuration of a node in a cluster.
+type ClusterNodeConfig struct {
+	Name string `json:"name"`
+	IP   string `json:"ip"`
+
+	// Node is "local" for the node the session is connected to, and "remote" otherwise.
+	Node string `json:"node"`
+}
+
+// IsLocal checks if the session is connected to this node.
+func (cn *ClusterNodeConfig) IsLocal() bool {
+	return cn.Node == "local"
+}
+
+// String returns the cluster interface and its nodes.
+func (cd *ClusterDetails) String() string {
+	return fmt.Sprintf("%v (%v nodes)", cd.Interface, len(cd.Nodes))
+}
+
+type clusterNodeListResponse struct {
+	Description string               `json:"description"`
+	Params      []ClusterNodeDetails `json:"params"`
+}
+
+type clusterNodeDetailsResponse struct {
+	Description string             `json:"description"`
+	Params      ClusterNodeDetails `json:"params"`
+}
+
+// ClusterNodeDetails contains the role and status of a node in a cluster.
+type ClusterNodeDetails struct {
+	Name    string            `json:"name"`
+	IP      string            `json:"ip"`
+	Role    ClusterRole       `json:"role"`
+	Status  ClusterNodeStatus `json:"status"`
+	Message string            `json:"message"`
+}
+
+// String returns the node's name, role, and status.
+func (cn *ClusterNodeDetails) String() string {
+	return fmt.Sprintf("%v (%v, %v)", cn.Name, cn.Role, cn.Status)
+}
+
+// IsActive checks if the node is the master of the cluster.
+func (cn *ClusterNodeDetails) IsActive() bool {
+	return cn.Role == ClusterRole_Master
+}
+
+// IsSynchronized checks if the node is online and its configuration is synchronized.
+func (cn *ClusterNodeDetails) IsSynchronized() bool {
+	return cn.Status == ClusterNodeStatus_Ok
+}
+
+// GetCluster returns the configuration of the cluster, or *nil* if the loadbalancer is not part of a cluster.
+func (s *ZapiSession) GetCluster() (*ClusterDetails, error) {
+	return s.GetClusterContext(context.Background())
+}
+
+// GetClusterContext returns the configuration of the cluster using the provided context.
+func (s *ZapiSession) GetClusterContext(ctx context.Context) (*ClusterDetails, error) {
+	var result *clusterDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "system", "cluster")
+
+	if err != nil {
+		// cluster not configured, or community edition?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Params, nil
+}
+
+// GetAllClusterNodes returns the role and status of all nodes in the cluster.
+func (s *ZapiSession) GetAllClusterNodes() ([]ClusterNodeDetails, error) {
+	return s.GetAllClusterNodesContext(context.Background())
+}
+
+// GetAllClusterNodesContext returns the role and status of all nodes in the cluster using the provided context.
+func (s *ZapiSession) GetAllClusterNodesContext(ctx context.Context) ([]ClusterNodeDetails, error) {
+	var result *clusterNodeListResponse
+
+	err := s.getForEntity(ctx, &result, "system", "cluster", "nodes")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Params, nil
+}
+
+// GetLocalClusterNode returns the role and status of the node the session is connected to,
+// or *nil* if the loadbalancer is not part of a cluster.
+func (s *ZapiSession) GetLocalClusterNode() (*ClusterNodeDetails, error) {
+	return s.GetLocalClusterNodeContext(context.Background())
+}
+
+// GetLocalClusterNodeContext returns the role and status of the node the session is connected to using the provided context.
+func (s *ZapiSession) GetLocalClusterNodeContext(ctx context.Context) (*ClusterNodeDetails, error) {
+	var result *clusterNodeDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "system", "cluster", "nodes", "localhost")
+
+	if err != nil {
+		// cluster not configured, or community edition?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Params, nil
+}
+
+// IsActiveNode checks if the session is connected to the active node, i.e. the master of the cluster.
+// Changes made on the backup node are overwritten by the master's configuration.
+// A loadbalancer, which is not part of a cluster, is always active.
+func (s *ZapiSession) IsActiveNode() (bool, error) {
+	return s.IsActiveNodeContext(context.Background())
+}
+
+// IsActiveNodeContext checks if the session is connected to the active node using the provided context.
+func (s *ZapiSession) IsActiveNodeContext(ctx context.Context) (bool, error) {
+	node, err := s.GetLocalClusterNodeContext(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	// not a cluster?
+	if node == nil {
+		return true, nil
+	}
+
+	return node.IsActive(), nil
+}
@@ -0,0 +1,94 @@
+package zevenetlb
+
+import (
+	"testing"
+)
+
+func TestIsActiveNode(t *testing.T) {
+	session, server := createFakeTestSessionEx(t, nil)
+
+	// not part of a cluster
+	cluster, err := session.GetCluster()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cluster != nil {
+		t.Fatalf("Expected no cluster: %v", cluster)
+	}
+
+	active, err := session.IsActiveNode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !active {
+		t.Fatal("Expected a standalone loadbalancer to be active")
+	}
+
+	// the backup node
+	server.SetClusterRole("backup")
+
+	active, err = session.IsActiveNode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active {
+		t.Fatal("Expected the backup node to be passive")
+	}
+
+	// the master node
+	server.SetClusterRole("master")
+
+	active, err = session.IsActiveNode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !active {
+		t.Fatal("Expected the master node to be active")
+	}
+}
+
+func TestGetCluster(t *testing.T) {
+	session, server := createFakeTestSessionEx(t, nil)
+
+	server.SetClusterRole("backup")
+
+	cluster, err := session.GetCluster()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Cluster: %v", cluster)
+
+	if cluster == nil || len(cluster.Nodes) != 2 || !cluster.Nodes[0].IsLocal() || cluster.Nodes[1].IsLocal() {
+		t.Fatalf("Unexpected cluster: %+v", cluster)
+	}
+
+	nodes, err := session.GetAllClusterNodes()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 2 || nodes[0].Role != ClusterRole_Backup || nodes[1].Role != ClusterRole_Master || !nodes[1].IsSynchronized() {
+		t.Fatalf("Unexpected cluster nodes: %+v", nodes)
+	}
+
+	local, err := session.GetLocalClusterNode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if local == nil || local.Name != cluster.Nodes[0].Name || local.IsActive() {
+		t.Fatalf("Unexpected local node: %+v", local)
+	}
+}
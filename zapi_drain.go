@@ -0,0 +1,233 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//
+// Draining Backends
+//
+
+// ErrWaitTimeout is returned when a backend does not reach the expected state in time.
+var ErrWaitTimeout = errors.New("wait timeout")
+
+// DrainOptions configures *DrainBackend()*. Unset durations use their defaults.
+type DrainOptions struct {
+	// Timeout is the maximum time to wait for the connections to close. Defaults to 5 minutes.
+	Timeout time.Duration
+
+	// PollInterval is the delay between two checks of the connection statistics. Defaults to 2 seconds.
+	PollInterval time.Duration
+
+	// CutOnTimeout enables the cut mode after the timeout, closing the remaining connections.
+	// Otherwise, an error matching *ErrWaitTimeout* is returned and the backend stays in drain mode.
+	CutOnTimeout bool
+
+	// OnPoll is called after each check of the connection statistics, if set.
+	OnPoll func(stats BackendStats)
+}
+
+// DrainResult summarizes the draining of a backend.
+type DrainResult struct {
+	FarmName    string
+	ServiceName string
+	BackendID   int
+
+	// InitialConnections is the number of established and pending connections when the drain started.
+	InitialConnections int
+
+	// RemainingConnections is the number of connections left, which is greater than 0 if they have been cut.
+	RemainingConnections int
+
+	// Cut is set if the remaining connections have been cut after the timeout.
+	Cut bool
+
+	Polls    int
+	Duration time.Duration
+}
+
+// String returns the backend and the outcome of the drain.
+func (dr *DrainResult) String() string {
+	if dr.Cut {
+		return fmt.Sprintf("%v/%v/%v: cut %v of %v connections after %v", dr.FarmName, dr.ServiceName, dr.BackendID, dr.RemainingConnections, dr.InitialConnections, dr.Duration)
+	}
+
+	return fmt.Sprintf("%v/%v/%v: drained %v connections in %v", dr.FarmName, dr.ServiceName, dr.BackendID, dr.InitialConnections, dr.Duration)
+}
+
+// DrainBackend puts a backend into maintenance in drain mode and waits until it has no connections left.
+// Use *RestoreBackend()* to put it back into service.
+func (s *ZapiSession) DrainBackend(backend *BackendDetails, options *DrainOptions) (*DrainResult, error) {
+	return s.DrainBackendContext(context.Background(), backend, options)
+}
+
+// DrainBackendContext puts a backend into maintenance in drain mode and waits until it has no connections left using the provided context.
+func (s *ZapiSession) DrainBackendContext(ctx context.Context, backend *BackendDetails, options *DrainOptions) (*DrainResult, error) {
+	if options == nil {
+		options = &DrainOptions{}
+	}
+
+	timeout := options.Timeout
+
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	result := &DrainResult{
+		FarmName:    backend.FarmName,
+		ServiceName: backend.ServiceName,
+		BackendID:   backend.ID,
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	// enter drain mode
+	err := s.SetBackendMaintenanceContext(ctx, backend, true, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		stats, err := s.waitForBackend(ctx, backend, result.Polls, options.PollInterval, deadline)
+
+		if err != nil {
+			return result, err
+		}
+
+		result.Polls++
+		result.Duration = time.Since(start)
+
+		if options.OnPoll != nil {
+			options.OnPoll(*stats)
+		}
+
+		connections := stats.Established + stats.Pending
+
+		if result.Polls == 1 {
+			result.InitialConnections = connections
+		}
+
+		result.RemainingConnections = connections
+
+		// idle?
+		if connections <= 0 {
+			return result, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			if !options.CutOnTimeout {
+				return result, fmt.Errorf("Backend %v/%v/%v still has %v connections after %v: %w", backend.FarmName, backend.ServiceName, backend.ID, connections, timeout, ErrWaitTimeout)
+			}
+
+			// escalate to cut mode
+			err = s.SetBackendMaintenanceContext(ctx, backend, true, true)
+
+			if err != nil {
+				return result, err
+			}
+
+			result.Cut = true
+
+			return result, nil
+		}
+	}
+}
+
+// RestoreOptions configures *RestoreBackend()*. Unset durations use their defaults.
+type RestoreOptions struct {
+	// Timeout is the maximum time to wait for the backend to be up. Defaults to 2 minutes.
+	Timeout time.Duration
+
+	// PollInterval is the delay between two checks of the backend status. Defaults to 2 seconds.
+	PollInterval time.Duration
+
+	// OnPoll is called after each check of the backend status, if set.
+	OnPoll func(stats BackendStats)
+}
+
+// RestoreBackend puts a backend back into service and waits until its status is up,
+// e.g. after farmguardian has checked it. Otherwise, an error matching *ErrWaitTimeout* is returned.
+func (s *ZapiSession) RestoreBackend(backend *BackendDetails, options *RestoreOptions) error {
+	return s.RestoreBackendContext(context.Background(), backend, options)
+}
+
+// RestoreBackendContext puts a backend back into service and waits until its status is up using the provided context.
+func (s *ZapiSession) RestoreBackendContext(ctx context.Context, backend *BackendDetails, options *RestoreOptions) error {
+	if options == nil {
+		options = &RestoreOptions{}
+	}
+
+	timeout := options.Timeout
+
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	// leave maintenance
+	err := s.SetBackendMaintenanceContext(ctx, backend, false, false)
+
+	if err != nil {
+		return err
+	}
+
+	for polls := 0; ; polls++ {
+		stats, err := s.waitForBackend(ctx, backend, polls, options.PollInterval, deadline)
+
+		if err != nil {
+			return err
+		}
+
+		if options.OnPoll != nil {
+			options.OnPoll(*stats)
+		}
+
+		if stats.Status == BackendStatus_Up {
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("Backend %v/%v/%v is %v after %v: %w", backend.FarmName, backend.ServiceName, backend.ID, stats.Status, timeout, ErrWaitTimeout)
+		}
+	}
+}
+
+// waitForBackend retrieves the statistics of a backend, waiting for the poll interval unless it is the first poll.
+// The wait ends at the deadline, so the last poll happens right at it.
+func (s *ZapiSession) waitForBackend(ctx context.Context, backend *BackendDetails, polls int, interval time.Duration, deadline time.Time) (*BackendStats, error) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	if polls > 0 {
+		if remaining := time.Until(deadline); remaining < interval {
+			interval = remaining
+		}
+
+		err := sleepContext(ctx, interval)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats, err := s.GetFarmStatsContext(ctx, backend.FarmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bs := stats.GetBackend(backend.ServiceName, backend.ID)
+
+	if bs == nil {
+		return nil, fmt.Errorf("Backend %v/%v/%v not found: %w", backend.FarmName, backend.ServiceName, backend.ID, ErrNotFound)
+	}
+
+	return bs, nil
+}
@@ -0,0 +1,164 @@
+package zevenetlb
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+// createDrainTestSession uses the fake loadbalancer, as connections cannot be simulated on a real one.
+func createDrainTestSession(t *testing.T, options *ConfigOptions) (*ZapiSession, *zevenettest.Server, *BackendDetails) {
+	session, server := createFakeTestSessionEx(t, options)
+
+	farm, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return session, server, &farm.Services[0].Backends[0]
+}
+
+func TestDrainBackend(t *testing.T) {
+	session, server, backend := createDrainTestSession(t, nil)
+
+	err := server.SetBackendConnections("samplefarm", "default", 0, 5, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the connections close after the second poll
+	result, err := session.DrainBackend(backend, &DrainOptions{
+		PollInterval: time.Millisecond,
+		OnPoll: func(stats BackendStats) {
+			if stats.Status != BackendStatus_Maintenance {
+				t.Errorf("Expected the backend to be in maintenance: %v", stats)
+			}
+
+			server.SetBackendConnections("samplefarm", "default", 0, 0, 0)
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Result: %v", result)
+
+	if result.Cut || result.Polls != 2 || result.InitialConnections != 6 || result.RemainingConnections != 0 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// restore
+	err = session.RestoreBackend(backend, &RestoreOptions{PollInterval: time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDrainBackendTimeout(t *testing.T) {
+	session, server, backend := createDrainTestSession(t, nil)
+
+	err := server.SetBackendConnections("samplefarm", "default", 0, 5, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &DrainOptions{
+		Timeout:      20 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	}
+
+	_, err = session.DrainBackend(backend, options)
+
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+
+	// escalate
+	options.CutOnTimeout = true
+
+	result, err := session.DrainBackend(backend, options)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Result: %v", result)
+
+	if !result.Cut || result.RemainingConnections != 5 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	stats, err := session.GetFarmStats("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := stats.GetBackend("default", 0); b.Established != 0 {
+		t.Fatalf("Expected the connections to be cut: %v", b)
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRestoreBackend(t *testing.T) {
+	var server *zevenettest.Server
+
+	// farmguardian reports the backend as down right after leaving maintenance
+	session, server, backend := createDrainTestSession(t, &ConfigOptions{
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				res, err := rt.RoundTrip(req)
+
+				if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/maintenance") {
+					server.SetBackendStatus("samplefarm", "default", 0, "down")
+				}
+
+				return res, err
+			})
+		},
+	})
+
+	// the backend is up after the second poll
+	polls := 0
+
+	err := session.RestoreBackend(backend, &RestoreOptions{
+		PollInterval: time.Millisecond,
+		OnPoll: func(stats BackendStats) {
+			polls++
+
+			server.SetBackendStatus("samplefarm", "default", 0, "up")
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if polls != 2 {
+		t.Fatalf("Expected 2 polls, got %v", polls)
+	}
+
+	// the backend stays down
+	err = session.RestoreBackend(backend, &RestoreOptions{
+		Timeout:      20 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package zevenetlb
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/netip"
 	"strconv"
 	"strings"
 )
@@ -49,11 +52,21 @@ func (fi *FarmInfo) String() string {
 	return fmt.Sprintf("%v (%v)", fi.FarmName, fi.Profile)
 }
 
+// VirtualAddr returns the virtual IP address of the farm.
+func (fi *FarmInfo) VirtualAddr() (netip.Addr, error) {
+	return parseIPAddress(fi.VirtualIP)
+}
+
 // GetAllFarms returns list os all available farms.
 func (s *ZapiSession) GetAllFarms() ([]FarmInfo, error) {
+	return s.GetAllFarmsContext(context.Background())
+}
+
+// GetAllFarmsContext returns list os all available farms using the provided context.
+func (s *ZapiSession) GetAllFarmsContext(ctx context.Context) ([]FarmInfo, error) {
 	var result *farmListResponse
 
-	err := s.getForEntity(&result, "farms")
+	err := s.getForEntity(ctx, &result, "farms")
 
 	if err != nil {
 		return nil, err
@@ -173,6 +186,11 @@ func (fd *FarmDetails) String() string {
 	return fmt.Sprintf("%v (%v)", fd.FarmName, fd.Listener)
 }
 
+// VirtualAddr returns the virtual IP address of the farm.
+func (fd *FarmDetails) VirtualAddr() (netip.Addr, error) {
+	return parseIPAddress(fd.VirtualIP)
+}
+
 // IsHTTP checks if the farm has HTTP or HTTPS support enabled.
 func (fd *FarmDetails) IsHTTP() bool {
 	return strings.HasPrefix(string(fd.Listener), "http")
@@ -196,16 +214,19 @@ func (fd *FarmDetails) GetService(serviceName string) (*ServiceDetails, error) {
 
 // GetFarm returns details on a specific farm.
 func (s *ZapiSession) GetFarm(farmName string) (*FarmDetails, error) {
+	return s.GetFarmContext(context.Background(), farmName)
+}
+
+// GetFarmContext returns details on a specific farm using the provided context.
+func (s *ZapiSession) GetFarmContext(ctx context.Context, farmName string) (*FarmDetails, error) {
 	var result *farmDetailsResponse
 
-	err := s.getForEntity(&result, "farms", farmName)
+	err := s.getForEntity(ctx, &result, "farms", farmName)
 
 	if err != nil {
 		// farm not found?
-		if v, ok := err.(RequestError); ok {
-			if strings.Contains(v.Message, "not found") {
-				return nil, nil
-			}
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
 		}
 
 		return nil, err
@@ -235,55 +256,67 @@ func (s *ZapiSession) GetFarm(farmName string) (*FarmDetails, error) {
 
 // DeleteFarm will delete an existing farm (or do nothing if missing)
 func (s *ZapiSession) DeleteFarm(farmName string) (bool, error) {
-	// retrieve farm details
-	farm, err := s.GetFarm(farmName)
+	return s.DeleteFarmContext(context.Background(), farmName)
+}
+
+// DeleteFarmContext will delete an existing farm (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteFarmContext(ctx context.Context, farmName string) (bool, error) {
+	// retrieve farm profile
+	profile, err := s.getFarmProfile(ctx, farmName)
 
 	if err != nil {
 		return false, err
 	}
 
 	// farm does not exist?
-	if farm == nil {
+	if profile == "" {
 		return false, nil
 	}
 
 	// delete the farm
-	return true, s.delete("farms", farmName)
+	return true, s.delete(ctx, "farms", farmName)
 }
 
-type farmCreate struct {
-	FarmName    string `json:"farmname"`
-	Profile     string `json:"profile"`
-	VirtualIP   string `json:"vip"`
-	VirtualPort int    `json:"vport"`
+type farmProfileResponse struct {
+	Params struct {
+		Listener FarmProfile `json:"listener"`
+	} `json:"params"`
 }
 
-// CreateFarmAsL4xNat creates a new L4Nat farm.
-// A newly created farm is in the *critical* state, due to the lack of services and backends.
-func (s *ZapiSession) CreateFarmAsL4xNat(farmName string, virtualIP string, virtualPort int) (*FarmDetails, error) {
-
-	// create the farm
-	req := farmCreate{
-		FarmName:    farmName,
-		Profile:     "l4xnat",
-		VirtualIP:   virtualIP,
-		VirtualPort: virtualPort,
-	}
+// getFarmProfile returns the profile of a farm of any kind, or an empty string if the farm does not exist.
+func (s *ZapiSession) getFarmProfile(ctx context.Context, farmName string) (FarmProfile, error) {
+	var result *farmProfileResponse
 
-	err := s.post(req, "farms")
+	err := s.getForEntity(ctx, &result, "farms", farmName)
 
 	if err != nil {
-		return nil, err
+		// farm not found?
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+
+		return "", err
 	}
 
-	// retrieve status
-	return s.GetFarm(farmName)
+	return result.Params.Listener, nil
+}
+
+type farmCreate struct {
+	FarmName    string `json:"farmname"`
+	Profile     string `json:"profile"`
+	VirtualIP   string `json:"vip"`
+	VirtualPort int    `json:"vport"`
 }
 
 // CreateFarmAsHTTP creates a new HTTP farm.
 // A newly created farm is in the *critical* state, due to the lack of services and backends.
 // The *virtualPort* is optional and can be 0, using port 80 as default.
 func (s *ZapiSession) CreateFarmAsHTTP(farmName string, virtualIP string, virtualPort int) (*FarmDetails, error) {
+	return s.CreateFarmAsHTTPContext(context.Background(), farmName, virtualIP, virtualPort)
+}
+
+// CreateFarmAsHTTPContext creates a new HTTP farm using the provided context.
+func (s *ZapiSession) CreateFarmAsHTTPContext(ctx context.Context, farmName string, virtualIP string, virtualPort int) (*FarmDetails, error) {
 	// set default HTTP port
 	if virtualPort <= 0 {
 		virtualPort = 80
@@ -297,27 +330,32 @@ func (s *ZapiSession) CreateFarmAsHTTP(farmName string, virtualIP string, virtua
 		VirtualPort: virtualPort,
 	}
 
-	err := s.post(req, "farms")
+	err := s.post(ctx, req, "farms")
 
 	if err != nil {
 		return nil, err
 	}
 
 	// retrieve status
-	return s.GetFarm(farmName)
+	return s.GetFarmContext(ctx, farmName)
 }
 
 // CreateFarmAsHTTPS creates a new HTTPS farm.
 // A newly created farm is in the *critical* state, due to the lack of services and backends.
 // The *virtualPort* is optional and can be 0, using port 443 as default.
 func (s *ZapiSession) CreateFarmAsHTTPS(farmName string, virtualIP string, virtualPort int, certFilename string) (*FarmDetails, error) {
+	return s.CreateFarmAsHTTPSContext(context.Background(), farmName, virtualIP, virtualPort, certFilename)
+}
+
+// CreateFarmAsHTTPSContext creates a new HTTPS farm using the provided context.
+func (s *ZapiSession) CreateFarmAsHTTPSContext(ctx context.Context, farmName string, virtualIP string, virtualPort int, certFilename string) (*FarmDetails, error) {
 	// set default HTTPS port
 	if virtualPort <= 0 {
 		virtualPort = 443
 	}
 
 	// create the farm
-	farm, err := s.CreateFarmAsHTTP(farmName, virtualIP, virtualPort)
+	farm, err := s.CreateFarmAsHTTPContext(ctx, farmName, virtualIP, virtualPort)
 
 	if err != nil {
 		return nil, err
@@ -330,15 +368,34 @@ func (s *ZapiSession) CreateFarmAsHTTPS(farmName string, virtualIP string, virtu
 	farm.DisableSSLv3 = false
 	farm.DisableTLSv1 = false
 
-	s.UpdateFarm(farm)
+	err = s.UpdateFarmContext(ctx, farm)
+
+	if err != nil {
+		return nil, err
+	}
 
-	return farm, nil
+	// bind the certificate
+	if certFilename != "" {
+		err = s.ReplaceFarmCertificatesContext(ctx, farmName, []string{certFilename})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// retrieve status
+	return s.GetFarmContext(ctx, farmName)
 }
 
 // UpdateFarm updates the HTTP/S farm.
 // This method does *not* update the *services*. Use *UpdateService()* instead.
 func (s *ZapiSession) UpdateFarm(farm *FarmDetails) error {
-	return s.put(farm, "farms", farm.FarmName)
+	return s.UpdateFarmContext(context.Background(), farm)
+}
+
+// UpdateFarmContext updates the HTTP/S farm using the provided context.
+func (s *ZapiSession) UpdateFarmContext(ctx context.Context, farm *FarmDetails) error {
+	return s.put(ctx, farm, "farms", farm.FarmName)
 }
 
 type farmAction struct {
@@ -347,23 +404,38 @@ type farmAction struct {
 
 // StartFarm will start a stopped farm.
 func (s *ZapiSession) StartFarm(farmName string) error {
+	return s.StartFarmContext(context.Background(), farmName)
+}
+
+// StartFarmContext will start a stopped farm using the provided context.
+func (s *ZapiSession) StartFarmContext(ctx context.Context, farmName string) error {
 	req := farmAction{Action: "start"}
 
-	return s.put(req, "farms", farmName, "actions")
+	return s.put(ctx, req, "farms", farmName, "actions")
 }
 
 // StopFarm will stop a running farm.
 func (s *ZapiSession) StopFarm(farmName string) error {
+	return s.StopFarmContext(context.Background(), farmName)
+}
+
+// StopFarmContext will stop a running farm using the provided context.
+func (s *ZapiSession) StopFarmContext(ctx context.Context, farmName string) error {
 	req := farmAction{Action: "stop"}
 
-	return s.put(req, "farms", farmName, "actions")
+	return s.put(ctx, req, "farms", farmName, "actions")
 }
 
 // RestartFarm will restart a running farm.
 func (s *ZapiSession) RestartFarm(farmName string) error {
+	return s.RestartFarmContext(context.Background(), farmName)
+}
+
+// RestartFarmContext will restart a running farm using the provided context.
+func (s *ZapiSession) RestartFarmContext(ctx context.Context, farmName string) error {
 	req := farmAction{Action: "restart"}
 
-	return s.put(req, "farms", farmName, "actions")
+	return s.put(ctx, req, "farms", farmName, "actions")
 }
 
 // CertificateInfo contains reference information on a certificate.
@@ -377,6 +449,133 @@ func (ci CertificateInfo) String() string {
 	return ci.Filename
 }
 
+// HasCertificate checks if the certificate is bound to the HTTPS farm.
+func (fd *FarmDetails) HasCertificate(filename string) bool {
+	for _, c := range fd.Certificates {
+		if c.Filename == filename {
+			return true
+		}
+	}
+
+	return false
+}
+
+type farmCertificateAdd struct {
+	Filename string `json:"file"`
+}
+
+// AddFarmCertificate binds a certificate to an HTTPS farm. The certificate is added to the end of the SNI list.
+// Use *UploadCertificate()* to upload the certificate first.
+func (s *ZapiSession) AddFarmCertificate(farmName string, filename string) error {
+	return s.AddFarmCertificateContext(context.Background(), farmName, filename)
+}
+
+// AddFarmCertificateContext binds a certificate to an HTTPS farm using the provided context.
+func (s *ZapiSession) AddFarmCertificateContext(ctx context.Context, farmName string, filename string) error {
+	req := farmCertificateAdd{
+		Filename: filename,
+	}
+
+	return s.post(ctx, req, "farms", farmName, "certificates")
+}
+
+// RemoveFarmCertificate unbinds a certificate from an HTTPS farm (or does nothing if the farm or certificate binding is missing).
+// An HTTPS farm requires at least one certificate.
+func (s *ZapiSession) RemoveFarmCertificate(farmName string, filename string) (bool, error) {
+	return s.RemoveFarmCertificateContext(context.Background(), farmName, filename)
+}
+
+// RemoveFarmCertificateContext unbinds a certificate from an HTTPS farm using the provided context.
+func (s *ZapiSession) RemoveFarmCertificateContext(ctx context.Context, farmName string, filename string) (bool, error) {
+	// retrieve farm details
+	farm, err := s.GetFarmContext(ctx, farmName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// farm or binding does not exist?
+	if farm == nil || !farm.HasCertificate(filename) {
+		return false, nil
+	}
+
+	// remove the certificate
+	return true, s.delete(ctx, "farms", farmName, "certificates", filename)
+}
+
+// ReorderFarmCertificates changes the order of the certificates of an HTTPS farm, which is used for SNI.
+// The *filenames* have to contain all certificates currently bound to the farm.
+// As the ZAPI only supports appending certificates, they are unbound and bound again in the requested order.
+func (s *ZapiSession) ReorderFarmCertificates(farmName string, filenames []string) error {
+	return s.ReorderFarmCertificatesContext(context.Background(), farmName, filenames)
+}
+
+// ReorderFarmCertificatesContext changes the order of the certificates of an HTTPS farm using the provided context.
+func (s *ZapiSession) ReorderFarmCertificatesContext(ctx context.Context, farmName string, filenames []string) error {
+	// retrieve farm details
+	farm, err := s.GetFarmContext(ctx, farmName)
+
+	if err != nil {
+		return err
+	}
+
+	if farm == nil {
+		return fmt.Errorf("Farm not found: %v", farmName)
+	}
+
+	if len(filenames) != len(farm.Certificates) {
+		return fmt.Errorf("Expected %v certificates for farm %v, but got %v", len(farm.Certificates), farmName, len(filenames))
+	}
+
+	for _, f := range filenames {
+		if !farm.HasCertificate(f) {
+			return fmt.Errorf("Certificate %v is not bound to farm %v", f, farmName)
+		}
+	}
+
+	return s.ReplaceFarmCertificatesContext(ctx, farmName, filenames)
+}
+
+// ReplaceFarmCertificates binds the certificates to an HTTPS farm in the given order and unbinds all others.
+// The *filenames* must not be empty, as an HTTPS farm requires at least one certificate.
+func (s *ZapiSession) ReplaceFarmCertificates(farmName string, filenames []string) error {
+	return s.ReplaceFarmCertificatesContext(context.Background(), farmName, filenames)
+}
+
+// ReplaceFarmCertificatesContext binds the certificates to an HTTPS farm in the given order using the provided context.
+func (s *ZapiSession) ReplaceFarmCertificatesContext(ctx context.Context, farmName string, filenames []string) error {
+	if len(filenames) <= 0 {
+		return errors.New("An HTTPS farm requires at least one certificate")
+	}
+
+	// retrieve farm details
+	farm, err := s.GetFarmContext(ctx, farmName)
+
+	if err != nil {
+		return err
+	}
+
+	if farm == nil {
+		return fmt.Errorf("Farm not found: %v", farmName)
+	}
+
+	current := make([]string, len(farm.Certificates))
+
+	for i, c := range farm.Certificates {
+		current[i] = c.Filename
+	}
+
+	for _, op := range planCertificates(farmName, current, filenames) {
+		err = op.execute(ctx, s)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type serviceDetailsResponse struct {
 	Description string         `json:"description"`
 	Params      ServiceDetails `json:"params"`
@@ -459,9 +658,10 @@ func (sd *ServiceDetails) GetBackend(backendID int) (*BackendDetails, error) {
 }
 
 // GetBackendByAddress retrieves a backend by its IP address and port, or returns *nil* if not found. The *port* is optional and can be 0.
+// IPv6 addresses match regardless of their notation, e.g. "2001:db8::1" and "2001:0db8:0:0:0:0:0:1".
 func (sd *ServiceDetails) GetBackendByAddress(ipAddress string, port int) (*BackendDetails, error) {
 	for _, s := range sd.Backends {
-		if s.IPAddress == ipAddress && (port <= 0 || s.Port == port) {
+		if sameIPAddress(s.IPAddress, ipAddress) && (port <= 0 || s.Port == port) {
 			return &s, nil
 		}
 	}
@@ -475,8 +675,13 @@ type serviceCreate struct {
 
 // DeleteService will delete an existing service (or do nothing if service or farm is missing)
 func (s *ZapiSession) DeleteService(farmName string, serviceName string) (bool, error) {
+	return s.DeleteServiceContext(context.Background(), farmName, serviceName)
+}
+
+// DeleteServiceContext will delete an existing service (or do nothing if service or farm is missing) using the provided context.
+func (s *ZapiSession) DeleteServiceContext(ctx context.Context, farmName string, serviceName string) (bool, error) {
 	// retrieve farm details
-	farm, err := s.GetFarm(farmName)
+	farm, err := s.GetFarmContext(ctx, farmName)
 
 	if err != nil {
 		return false, err
@@ -499,24 +704,29 @@ func (s *ZapiSession) DeleteService(farmName string, serviceName string) (bool,
 	}
 
 	// delete the service
-	return true, s.delete("farms", farmName, "services", serviceName)
+	return true, s.delete(ctx, "farms", farmName, "services", serviceName)
 }
 
 // CreateService creates a new service on a farm.
 func (s *ZapiSession) CreateService(farmName string, serviceName string) (*ServiceDetails, error) {
+	return s.CreateServiceContext(context.Background(), farmName, serviceName)
+}
+
+// CreateServiceContext creates a new service on a farm using the provided context.
+func (s *ZapiSession) CreateServiceContext(ctx context.Context, farmName string, serviceName string) (*ServiceDetails, error) {
 	// create the service
 	req := serviceCreate{
 		ServiceName: serviceName,
 	}
 
-	err := s.post(req, "farms", farmName, "services")
+	err := s.post(ctx, req, "farms", farmName, "services")
 
 	if err != nil {
 		return nil, err
 	}
 
 	// retrieve status
-	farm, err := s.GetFarm(farmName)
+	farm, err := s.GetFarmContext(ctx, farmName)
 
 	if err != nil {
 		return nil, err
@@ -536,7 +746,12 @@ type farmguardianUpdate struct {
 // UpdateService updates a service on a farm.
 // This method does *not* update the *backends*. Use *UpdateBackend()* instead.
 func (s *ZapiSession) UpdateService(service *ServiceDetails) error {
-	err := s.put(service, "farms", service.FarmName, "services", service.ServiceName)
+	return s.UpdateServiceContext(context.Background(), service)
+}
+
+// UpdateServiceContext updates a service on a farm using the provided context.
+func (s *ZapiSession) UpdateServiceContext(ctx context.Context, service *ServiceDetails) error {
+	err := s.put(ctx, service, "farms", service.FarmName, "services", service.ServiceName)
 
 	if err != nil {
 		return err
@@ -555,7 +770,7 @@ func (s *ZapiSession) UpdateService(service *ServiceDetails) error {
 		fg.FarmGuardianScript = "check_http -H HOST -p PORT"
 	}
 
-	return s.put(fg, "farms", service.FarmName, "fg")
+	return s.put(ctx, fg, "farms", service.FarmName, "fg")
 }
 
 type backendDetailsResponse struct {
@@ -597,6 +812,26 @@ func (bd BackendDetails) String() string {
 	return fmt.Sprintf("%v:%v (ID: %v, Status: %v)", bd.IPAddress, bd.Port, bd.ID, bd.Status)
 }
 
+// Addr returns the IP address of the backend.
+func (bd BackendDetails) Addr() (netip.Addr, error) {
+	return parseIPAddress(bd.IPAddress)
+}
+
+// AddrPort returns the IP address and port of the backend.
+func (bd BackendDetails) AddrPort() (netip.AddrPort, error) {
+	addr, err := bd.Addr()
+
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+
+	if bd.Port <= 0 || bd.Port > 65535 {
+		return netip.AddrPort{}, fmt.Errorf("Invalid port %v", bd.Port)
+	}
+
+	return netip.AddrPortFrom(addr, uint16(bd.Port)), nil
+}
+
 type backendCreate struct {
 	IPAddress string `json:"ip"`
 	Port      int    `json:"port"`
@@ -604,8 +839,13 @@ type backendCreate struct {
 
 // DeleteBackend will delete an existing backend (or do nothing if backend or service or farm is missing)
 func (s *ZapiSession) DeleteBackend(farmName string, serviceName string, backendId int) (bool, error) {
+	return s.DeleteBackendContext(context.Background(), farmName, serviceName, backendId)
+}
+
+// DeleteBackendContext will delete an existing backend (or do nothing if backend or service or farm is missing) using the provided context.
+func (s *ZapiSession) DeleteBackendContext(ctx context.Context, farmName string, serviceName string, backendId int) (bool, error) {
 	// retrieve farm details
-	farm, err := s.GetFarm(farmName)
+	farm, err := s.GetFarmContext(ctx, farmName)
 
 	if err != nil {
 		return false, err
@@ -639,25 +879,30 @@ func (s *ZapiSession) DeleteBackend(farmName string, serviceName string, backend
 	}
 
 	// delete the backend
-	return true, s.delete("farms", farmName, "services", serviceName, "backends", strconv.Itoa(backendId))
+	return true, s.delete(ctx, "farms", farmName, "services", serviceName, "backends", strconv.Itoa(backendId))
 }
 
 // CreateBackend creates a new backend on a service on a farm.
 func (s *ZapiSession) CreateBackend(farmName string, serviceName string, backendIP string, backendPort int) (*BackendDetails, error) {
+	return s.CreateBackendContext(context.Background(), farmName, serviceName, backendIP, backendPort)
+}
+
+// CreateBackendContext creates a new backend on a service on a farm using the provided context.
+func (s *ZapiSession) CreateBackendContext(ctx context.Context, farmName string, serviceName string, backendIP string, backendPort int) (*BackendDetails, error) {
 	// create the backend
 	req := backendCreate{
 		IPAddress: backendIP,
 		Port:      backendPort,
 	}
 
-	err := s.post(req, "farms", farmName, "services", serviceName, "backends")
+	err := s.post(ctx, req, "farms", farmName, "services", serviceName, "backends")
 
 	if err != nil {
 		return nil, err
 	}
 
 	// retrieve status
-	farm, err := s.GetFarm(farmName)
+	farm, err := s.GetFarmContext(ctx, farmName)
 
 	if err != nil {
 		return nil, err
@@ -674,7 +919,12 @@ func (s *ZapiSession) CreateBackend(farmName string, serviceName string, backend
 
 // UpdateBackend updates a backend on a service on a farm.
 func (s *ZapiSession) UpdateBackend(backend *BackendDetails) error {
-	return s.put(backend, "farms", backend.FarmName, "services", backend.ServiceName, "backends", strconv.Itoa(backend.ID))
+	return s.UpdateBackendContext(context.Background(), backend)
+}
+
+// UpdateBackendContext updates a backend on a service on a farm using the provided context.
+func (s *ZapiSession) UpdateBackendContext(ctx context.Context, backend *BackendDetails) error {
+	return s.put(ctx, backend, "farms", backend.FarmName, "services", backend.ServiceName, "backends", strconv.Itoa(backend.ID))
 }
 
 type backendMaintenance struct {
@@ -685,6 +935,11 @@ type backendMaintenance struct {
 // SetBackendMaintenance updates a backend on a service on a farm.
 // To cut and disconnect any existing connections when enabling maintenance, set *cutExistingConnections* to true.
 func (s *ZapiSession) SetBackendMaintenance(backend *BackendDetails, enableMaintenance bool, cutExistingConnections bool) error {
+	return s.SetBackendMaintenanceContext(context.Background(), backend, enableMaintenance, cutExistingConnections)
+}
+
+// SetBackendMaintenanceContext updates a backend on a service on a farm using the provided context.
+func (s *ZapiSession) SetBackendMaintenanceContext(ctx context.Context, backend *BackendDetails, enableMaintenance bool, cutExistingConnections bool) error {
 	var cmd backendMaintenance
 
 	if enableMaintenance {
@@ -707,5 +962,5 @@ func (s *ZapiSession) SetBackendMaintenance(backend *BackendDetails, enableMaint
 		}
 	}
 
-	return s.put(cmd, "farms", backend.FarmName, "services", backend.ServiceName, "backends", strconv.Itoa(backend.ID), "maintenance")
+	return s.put(ctx, cmd, "farms", backend.FarmName, "services", backend.ServiceName, "backends", strconv.Itoa(backend.ID), "maintenance")
 }
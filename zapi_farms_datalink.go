@@ -0,0 +1,312 @@
+package zevenetlb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+type dataLinkFarmDetailsResponse struct {
+	Description string                   `json:"description"`
+	Params      json.RawMessage          `json:"params"`
+	Backends    []DataLinkBackendDetails `json:"backends"`
+}
+
+// DataLinkFarmAlgorithm is an enumeration of possible selections of *Algorithm* values.
+type DataLinkFarmAlgorithm string
+
+const (
+	// DataLinkFarmAlgorithm_Weight balances the traffic depending on the *Weight* of the uplinks.
+	DataLinkFarmAlgorithm_Weight DataLinkFarmAlgorithm = "weight"
+
+	// DataLinkFarmAlgorithm_Priority sends all traffic to the uplink with the lowest *Priority* value that is up.
+	DataLinkFarmAlgorithm_Priority DataLinkFarmAlgorithm = "prio"
+)
+
+// DataLinkFarmDetails contains all information regarding a DATALINK farm and its backends (uplinks).
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#retrieve-farm-by-name
+type DataLinkFarmDetails struct {
+	FarmName  string                   `json:"farmname"`
+	Algorithm DataLinkFarmAlgorithm    `json:"algorithm"`
+	Status    FarmStatus               `json:"status"`
+	VirtualIP string                   `json:"vip"`
+	Backends  []DataLinkBackendDetails `json:"backends"`
+}
+
+// String returns the farm's name and virtual IP.
+func (fd *DataLinkFarmDetails) String() string {
+	return fmt.Sprintf("%v (datalink, %v)", fd.FarmName, fd.VirtualIP)
+}
+
+// VirtualAddr returns the virtual IP address of the farm.
+func (fd *DataLinkFarmDetails) VirtualAddr() (netip.Addr, error) {
+	return parseIPAddress(fd.VirtualIP)
+}
+
+// IsRunning checks if the farm is up and running.
+func (fd *DataLinkFarmDetails) IsRunning() bool {
+	return fd.Status == FarmStatus_Up
+}
+
+// GetBackend retrieves a backend by its ID, or returns *nil* if not found.
+func (fd *DataLinkFarmDetails) GetBackend(backendID int) (*DataLinkBackendDetails, error) {
+	for _, b := range fd.Backends {
+		if b.ID == backendID {
+			return &b, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetBackendByGateway retrieves a backend by its gateway IP address and interface, or returns *nil* if not found. The *interfaceName* is optional and can be empty.
+func (fd *DataLinkFarmDetails) GetBackendByGateway(gatewayIP string, interfaceName string) (*DataLinkBackendDetails, error) {
+	for _, b := range fd.Backends {
+		if sameIPAddress(b.GatewayIP, gatewayIP) && (interfaceName == "" || b.Interface == interfaceName) {
+			return &b, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetDataLinkFarm returns details on a specific DATALINK farm, or returns *nil* if not found.
+func (s *ZapiSession) GetDataLinkFarm(farmName string) (*DataLinkFarmDetails, error) {
+	return s.GetDataLinkFarmContext(context.Background(), farmName)
+}
+
+// GetDataLinkFarmContext returns details on a specific DATALINK farm using the provided context.
+func (s *ZapiSession) GetDataLinkFarmContext(ctx context.Context, farmName string) (*DataLinkFarmDetails, error) {
+	var result *dataLinkFarmDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "farms", farmName)
+
+	if err != nil {
+		// farm not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// check the profile before decoding, the HTTP fields do not match
+	var profile farmProfileResponse
+
+	if err := json.Unmarshal(result.Params, &profile.Params); err != nil {
+		return nil, err
+	}
+
+	if profile.Params.Listener != FarmProfile_DataLink {
+		return nil, fmt.Errorf("Farm %v is not a %v farm: %v", farmName, FarmProfile_DataLink, profile.Params.Listener)
+	}
+
+	var farm DataLinkFarmDetails
+
+	if err := json.Unmarshal(result.Params, &farm); err != nil {
+		return nil, err
+	}
+
+	// inject values
+	farm.FarmName = farmName
+	farm.Backends = result.Backends
+
+	for b := range farm.Backends {
+		farm.Backends[b].FarmName = farmName
+	}
+
+	return &farm, nil
+}
+
+type dataLinkFarmCreate struct {
+	FarmName  string `json:"farmname"`
+	Profile   string `json:"profile"`
+	VirtualIP string `json:"vip"`
+}
+
+// CreateFarmAsDataLink creates a new DATALINK farm, balancing the outgoing traffic of *virtualIP* across multiple uplinks.
+// A newly created farm is in the *critical* state, due to the lack of backends.
+func (s *ZapiSession) CreateFarmAsDataLink(farmName string, virtualIP string) (*DataLinkFarmDetails, error) {
+	return s.CreateFarmAsDataLinkContext(context.Background(), farmName, virtualIP)
+}
+
+// CreateFarmAsDataLinkContext creates a new DATALINK farm using the provided context.
+func (s *ZapiSession) CreateFarmAsDataLinkContext(ctx context.Context, farmName string, virtualIP string) (*DataLinkFarmDetails, error) {
+	// create the farm
+	req := dataLinkFarmCreate{
+		FarmName:  farmName,
+		Profile:   string(FarmProfile_DataLink),
+		VirtualIP: virtualIP,
+	}
+
+	err := s.post(ctx, req, "farms")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetDataLinkFarmContext(ctx, farmName)
+}
+
+type dataLinkFarmUpdate struct {
+	Algorithm DataLinkFarmAlgorithm `json:"algorithm"`
+	VirtualIP string                `json:"vip"`
+}
+
+// UpdateDataLinkFarm updates the DATALINK farm.
+// This method does *not* update the *backends*. Use *UpdateDataLinkBackend()* instead.
+func (s *ZapiSession) UpdateDataLinkFarm(farm *DataLinkFarmDetails) error {
+	return s.UpdateDataLinkFarmContext(context.Background(), farm)
+}
+
+// UpdateDataLinkFarmContext updates the DATALINK farm using the provided context.
+func (s *ZapiSession) UpdateDataLinkFarmContext(ctx context.Context, farm *DataLinkFarmDetails) error {
+	req := dataLinkFarmUpdate{
+		Algorithm: farm.Algorithm,
+		VirtualIP: farm.VirtualIP,
+	}
+
+	return s.put(ctx, req, "farms", farm.FarmName)
+}
+
+// DeleteDataLinkFarm will delete an existing DATALINK farm (or do nothing if missing)
+func (s *ZapiSession) DeleteDataLinkFarm(farmName string) (bool, error) {
+	return s.DeleteDataLinkFarmContext(context.Background(), farmName)
+}
+
+// DeleteDataLinkFarmContext will delete an existing DATALINK farm (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteDataLinkFarmContext(ctx context.Context, farmName string) (bool, error) {
+	// retrieve farm details
+	farm, err := s.GetDataLinkFarmContext(ctx, farmName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// farm does not exist?
+	if farm == nil {
+		return false, nil
+	}
+
+	// delete the farm
+	return true, s.delete(ctx, "farms", farmName)
+}
+
+// DataLinkBackendDetails contains all information regarding a single backend (uplink) of a DATALINK farm.
+type DataLinkBackendDetails struct {
+	ID        int           `json:"id"`
+	GatewayIP string        `json:"ip"`
+	Interface string        `json:"interface"`
+	Priority  int           `json:"priority"`
+	Weight    int           `json:"weight"`
+	Status    BackendStatus `json:"status"`
+	FarmName  string        `json:"farmname"`
+}
+
+// String returns the backend's gateway, interface, ID, and status.
+func (bd DataLinkBackendDetails) String() string {
+	return fmt.Sprintf("%v via %v (ID: %v, Status: %v)", bd.GatewayIP, bd.Interface, bd.ID, bd.Status)
+}
+
+// GatewayAddr returns the gateway IP address of the backend.
+func (bd DataLinkBackendDetails) GatewayAddr() (netip.Addr, error) {
+	return parseIPAddress(bd.GatewayIP)
+}
+
+type dataLinkBackendUpdate struct {
+	GatewayIP string `json:"ip"`
+	Interface string `json:"interface"`
+	Priority  int    `json:"priority,omitempty"`
+	Weight    int    `json:"weight,omitempty"`
+}
+
+// CreateDataLinkBackend creates a new backend (uplink) on a DATALINK farm.
+// The *weight* and *priority* are optional and can be 0, using 1 as default.
+func (s *ZapiSession) CreateDataLinkBackend(farmName string, gatewayIP string, interfaceName string, weight int, priority int) (*DataLinkBackendDetails, error) {
+	return s.CreateDataLinkBackendContext(context.Background(), farmName, gatewayIP, interfaceName, weight, priority)
+}
+
+// CreateDataLinkBackendContext creates a new backend (uplink) on a DATALINK farm using the provided context.
+func (s *ZapiSession) CreateDataLinkBackendContext(ctx context.Context, farmName string, gatewayIP string, interfaceName string, weight int, priority int) (*DataLinkBackendDetails, error) {
+	// create the backend
+	req := dataLinkBackendUpdate{
+		GatewayIP: gatewayIP,
+		Interface: interfaceName,
+		Priority:  priority,
+		Weight:    weight,
+	}
+
+	err := s.post(ctx, req, "farms", farmName, "backends")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	farm, err := s.GetDataLinkFarmContext(ctx, farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if farm == nil {
+		return nil, fmt.Errorf("Farm not found: %v", farmName)
+	}
+
+	return farm.GetBackendByGateway(gatewayIP, interfaceName)
+}
+
+// UpdateDataLinkBackend updates a backend (uplink) on a DATALINK farm.
+func (s *ZapiSession) UpdateDataLinkBackend(backend *DataLinkBackendDetails) error {
+	return s.UpdateDataLinkBackendContext(context.Background(), backend)
+}
+
+// UpdateDataLinkBackendContext updates a backend (uplink) on a DATALINK farm using the provided context.
+func (s *ZapiSession) UpdateDataLinkBackendContext(ctx context.Context, backend *DataLinkBackendDetails) error {
+	req := dataLinkBackendUpdate{
+		GatewayIP: backend.GatewayIP,
+		Interface: backend.Interface,
+		Priority:  backend.Priority,
+		Weight:    backend.Weight,
+	}
+
+	return s.put(ctx, req, "farms", backend.FarmName, "backends", strconv.Itoa(backend.ID))
+}
+
+// DeleteDataLinkBackend will delete an existing backend (or do nothing if backend or farm is missing)
+func (s *ZapiSession) DeleteDataLinkBackend(farmName string, backendID int) (bool, error) {
+	return s.DeleteDataLinkBackendContext(context.Background(), farmName, backendID)
+}
+
+// DeleteDataLinkBackendContext will delete an existing backend (or do nothing if backend or farm is missing) using the provided context.
+func (s *ZapiSession) DeleteDataLinkBackendContext(ctx context.Context, farmName string, backendID int) (bool, error) {
+	// retrieve farm details
+	farm, err := s.GetDataLinkFarmContext(ctx, farmName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// farm does not exist?
+	if farm == nil {
+		return false, nil
+	}
+
+	// does the backend exist?
+	backend, err := farm.GetBackend(backendID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if backend == nil {
+		return false, nil
+	}
+
+	// delete the backend
+	return true, s.delete(ctx, "farms", farmName, "backends", strconv.Itoa(backendID))
+}
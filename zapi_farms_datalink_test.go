@@ -0,0 +1,86 @@
+package zevenetlb
+
+import (
+	"testing"
+)
+
+const (
+	unitTestDataLinkFarmName = "UNITTESTGODL"
+	unitTestDataLinkGateway  = "10.209.0.1"
+	unitTestDataLinkNIC      = "eth0"
+)
+
+func TestRoundtripDataLinkFarm(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the farm does not exist
+	_, err := session.DeleteDataLinkFarm(unitTestDataLinkFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create the new farm
+	farm, err := session.CreateFarmAsDataLink(unitTestDataLinkFarmName, unitTestVirtualIP)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteDataLinkFarm(unitTestDataLinkFarmName)
+
+	t.Logf("New farm: %v, Status: %v", farm, farm.Status)
+
+	// update the farm
+	farm.Algorithm = DataLinkFarmAlgorithm_Priority
+
+	err = session.UpdateDataLinkFarm(farm)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add a backend
+	backend, err := session.CreateDataLinkBackend(farm.FarmName, unitTestDataLinkGateway, unitTestDataLinkNIC, 1, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backend == nil {
+		t.Fatal("Backend not found after creation")
+	}
+
+	t.Logf("Backend: %v", backend)
+
+	// update the backend
+	backend.Priority = 1
+
+	err = session.UpdateDataLinkBackend(backend)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cleaning up, delete the backend
+	deleted, err := session.DeleteDataLinkBackend(farm.FarmName, backend.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the backend to succeed, but failed")
+	}
+
+	// done, delete the farm
+	deleted, err = session.DeleteDataLinkFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the farm to succeed, but failed")
+	}
+}
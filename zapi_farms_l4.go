@@ -0,0 +1,420 @@
+package zevenetlb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+type l4FarmDetailsResponse struct {
+	Description string             `json:"description"`
+	Params      json.RawMessage    `json:"params"`
+	Backends    []L4BackendDetails `json:"backends"`
+}
+
+// L4FarmProtocol is an enumeration of possible selections of *Protocol* values.
+type L4FarmProtocol string
+
+const (
+	L4FarmProtocol_All  L4FarmProtocol = "all"
+	L4FarmProtocol_TCP  L4FarmProtocol = "tcp"
+	L4FarmProtocol_UDP  L4FarmProtocol = "udp"
+	L4FarmProtocol_SCTP L4FarmProtocol = "sctp"
+	L4FarmProtocol_SIP  L4FarmProtocol = "sip"
+	L4FarmProtocol_FTP  L4FarmProtocol = "ftp"
+	L4FarmProtocol_TFTP L4FarmProtocol = "tftp"
+)
+
+// L4FarmNATType is an enumeration of possible selections of *NATType* values.
+type L4FarmNATType string
+
+const (
+	// L4FarmNATType_NAT means the backend responds to the loadbalancer, which sends the response to the client.
+	L4FarmNATType_NAT L4FarmNATType = "nat"
+
+	// L4FarmNATType_DNAT means the backend responds to the client directly, the loadbalancer has to be the default gateway of the backend.
+	L4FarmNATType_DNAT L4FarmNATType = "dnat"
+
+	// L4FarmNATType_SNAT means the source address of the requests is replaced by the virtual IP of the farm.
+	L4FarmNATType_SNAT L4FarmNATType = "snat"
+)
+
+// L4FarmAlgorithm is an enumeration of possible selections of *Algorithm* values.
+type L4FarmAlgorithm string
+
+const (
+	// L4FarmAlgorithm_Weight balances the connections depending on the *Weight* of the backends.
+	L4FarmAlgorithm_Weight L4FarmAlgorithm = "weight"
+
+	// L4FarmAlgorithm_LeastConnections balances the connections to the backend with the least active connections.
+	L4FarmAlgorithm_LeastConnections L4FarmAlgorithm = "leastconn"
+
+	// L4FarmAlgorithm_Priority sends all connections to the backend with the lowest *Priority* value that is up.
+	L4FarmAlgorithm_Priority L4FarmAlgorithm = "prio"
+
+	// L4FarmAlgorithm_HashSourceIP balances the connections depending on a hash of the client IP.
+	L4FarmAlgorithm_HashSourceIP L4FarmAlgorithm = "hash_srcip"
+
+	// L4FarmAlgorithm_HashSourceIPPort balances the connections depending on a hash of the client IP and port.
+	L4FarmAlgorithm_HashSourceIPPort L4FarmAlgorithm = "hash_srcip_srcport"
+)
+
+// L4FarmPersistence is an enumeration of possible selections of *Persistence* values.
+type L4FarmPersistence string
+
+const (
+	// L4FarmPersistence_Disabled means no action is taken.
+	L4FarmPersistence_Disabled L4FarmPersistence = ""
+
+	// L4FarmPersistence_IPAddress means the connections of a client IP are sent to the same backend.
+	L4FarmPersistence_IPAddress L4FarmPersistence = "ip"
+)
+
+// L4FarmDetails contains all information regarding a L4xNAT farm and its backends.
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#retrieve-farm-by-name
+type L4FarmDetails struct {
+	FarmName                         string             `json:"farmname"`
+	Algorithm                        L4FarmAlgorithm    `json:"algorithm"`
+	NATType                          L4FarmNATType      `json:"nattype"`
+	Persistence                      L4FarmPersistence  `json:"persistence"`
+	PersistenceTimeoutSeconds        int                `json:"ttl"`
+	Protocol                         L4FarmProtocol     `json:"protocol"`
+	Status                           FarmStatus         `json:"status"`
+	VirtualIP                        string             `json:"vip"`
+	VirtualPorts                     string             `json:"vport"`
+	FarmGuardianEnabled              bool               `json:"fgenabled,string"`
+	FarmGuardianLogsEnabled          OptionalBool       `json:"fglog"`
+	FarmGuardianScript               string             `json:"fgscript"`
+	FarmGuardianCheckIntervalSeconds int                `json:"fgtimecheck"`
+	Backends                         []L4BackendDetails `json:"backends"`
+}
+
+// String returns the farm's name and protocol.
+func (fd *L4FarmDetails) String() string {
+	return fmt.Sprintf("%v (l4xnat, %v)", fd.FarmName, fd.Protocol)
+}
+
+// VirtualAddr returns the virtual IP address of the farm.
+func (fd *L4FarmDetails) VirtualAddr() (netip.Addr, error) {
+	return parseIPAddress(fd.VirtualIP)
+}
+
+// IsRunning checks if the farm is up and running.
+func (fd *L4FarmDetails) IsRunning() bool {
+	return fd.Status == FarmStatus_Up
+}
+
+// GetBackend retrieves a backend by its ID, or returns *nil* if not found.
+func (fd *L4FarmDetails) GetBackend(backendID int) (*L4BackendDetails, error) {
+	for _, b := range fd.Backends {
+		if b.ID == backendID {
+			return &b, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetBackendByAddress retrieves a backend by its IP address and port, or returns *nil* if not found. The *port* is optional and can be empty.
+// IPv6 addresses match regardless of their notation.
+func (fd *L4FarmDetails) GetBackendByAddress(ipAddress string, port string) (*L4BackendDetails, error) {
+	for _, b := range fd.Backends {
+		if sameIPAddress(b.IPAddress, ipAddress) && (port == "" || b.Port == port) {
+			return &b, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetL4Farm returns details on a specific L4xNAT farm, or returns *nil* if not found.
+func (s *ZapiSession) GetL4Farm(farmName string) (*L4FarmDetails, error) {
+	return s.GetL4FarmContext(context.Background(), farmName)
+}
+
+// GetL4FarmContext returns details on a specific L4xNAT farm using the provided context.
+func (s *ZapiSession) GetL4FarmContext(ctx context.Context, farmName string) (*L4FarmDetails, error) {
+	var result *l4FarmDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "farms", farmName)
+
+	if err != nil {
+		// farm not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// check the profile before decoding, the HTTP fields do not match
+	var profile farmProfileResponse
+
+	if err := json.Unmarshal(result.Params, &profile.Params); err != nil {
+		return nil, err
+	}
+
+	if profile.Params.Listener != FarmProfile_Level4NAT {
+		return nil, fmt.Errorf("Farm %v is not a %v farm: %v", farmName, FarmProfile_Level4NAT, profile.Params.Listener)
+	}
+
+	var farm L4FarmDetails
+
+	if err := json.Unmarshal(result.Params, &farm); err != nil {
+		return nil, err
+	}
+
+	// inject values
+	farm.FarmName = farmName
+	farm.Backends = result.Backends
+
+	for b := range farm.Backends {
+		farm.Backends[b].FarmName = farmName
+	}
+
+	return &farm, nil
+}
+
+// CreateFarmAsL4xNat creates a new L4xNAT farm.
+// A newly created farm is in the *critical* state, due to the lack of backends.
+// Only the name, status, virtual IP, and virtual port are returned, use *CreateL4xNatFarm()* for the L4xNAT settings.
+func (s *ZapiSession) CreateFarmAsL4xNat(farmName string, virtualIP string, virtualPort int) (*FarmDetails, error) {
+	return s.CreateFarmAsL4xNatContext(context.Background(), farmName, virtualIP, virtualPort)
+}
+
+// CreateFarmAsL4xNatContext creates a new L4xNAT farm using the provided context.
+func (s *ZapiSession) CreateFarmAsL4xNatContext(ctx context.Context, farmName string, virtualIP string, virtualPort int) (*FarmDetails, error) {
+	farm, err := s.CreateL4xNatFarmContext(ctx, farmName, virtualIP, virtualPort)
+
+	if err != nil || farm == nil {
+		return nil, err
+	}
+
+	// the L4xNAT fields do not match the HTTP farm details
+	return &FarmDetails{
+		FarmName:    farm.FarmName,
+		Listener:    FarmListener(FarmProfile_Level4NAT),
+		Status:      farm.Status,
+		VirtualIP:   farm.VirtualIP,
+		VirtualPort: virtualPort,
+	}, nil
+}
+
+// CreateL4xNatFarm creates a new L4xNAT farm and returns its L4xNAT details.
+// A newly created farm is in the *critical* state, due to the lack of backends.
+func (s *ZapiSession) CreateL4xNatFarm(farmName string, virtualIP string, virtualPort int) (*L4FarmDetails, error) {
+	return s.CreateL4xNatFarmContext(context.Background(), farmName, virtualIP, virtualPort)
+}
+
+// CreateL4xNatFarmContext creates a new L4xNAT farm and returns its L4xNAT details using the provided context.
+func (s *ZapiSession) CreateL4xNatFarmContext(ctx context.Context, farmName string, virtualIP string, virtualPort int) (*L4FarmDetails, error) {
+	// create the farm
+	req := farmCreate{
+		FarmName:    farmName,
+		Profile:     string(FarmProfile_Level4NAT),
+		VirtualIP:   virtualIP,
+		VirtualPort: virtualPort,
+	}
+
+	err := s.post(ctx, req, "farms")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetL4FarmContext(ctx, farmName)
+}
+
+type l4FarmUpdate struct {
+	Algorithm                 L4FarmAlgorithm   `json:"algorithm"`
+	NATType                   L4FarmNATType     `json:"nattype"`
+	Persistence               L4FarmPersistence `json:"persistence"`
+	PersistenceTimeoutSeconds int               `json:"ttl"`
+	Protocol                  L4FarmProtocol    `json:"protocol"`
+	VirtualIP                 string            `json:"vip"`
+	VirtualPorts              string            `json:"vport"`
+}
+
+type l4FarmguardianUpdate struct {
+	FarmGuardianEnabled              bool         `json:"fgenabled,string"`
+	FarmGuardianLogsEnabled          OptionalBool `json:"fglog"`
+	FarmGuardianScript               string       `json:"fgscript"`
+	FarmGuardianCheckIntervalSeconds int          `json:"fgtimecheck"`
+}
+
+// UpdateL4Farm updates the L4xNAT farm, including its farm guardian settings.
+// This method does *not* update the *backends*. Use *UpdateL4Backend()* instead.
+func (s *ZapiSession) UpdateL4Farm(farm *L4FarmDetails) error {
+	return s.UpdateL4FarmContext(context.Background(), farm)
+}
+
+// UpdateL4FarmContext updates the L4xNAT farm using the provided context.
+func (s *ZapiSession) UpdateL4FarmContext(ctx context.Context, farm *L4FarmDetails) error {
+	req := l4FarmUpdate{
+		Algorithm:                 farm.Algorithm,
+		NATType:                   farm.NATType,
+		Persistence:               farm.Persistence,
+		PersistenceTimeoutSeconds: farm.PersistenceTimeoutSeconds,
+		Protocol:                  farm.Protocol,
+		VirtualIP:                 farm.VirtualIP,
+		VirtualPorts:              farm.VirtualPorts,
+	}
+
+	err := s.put(ctx, req, "farms", farm.FarmName)
+
+	if err != nil {
+		return err
+	}
+
+	// update farm guardian
+	fg := l4FarmguardianUpdate{
+		FarmGuardianEnabled:              farm.FarmGuardianEnabled,
+		FarmGuardianLogsEnabled:          farm.FarmGuardianLogsEnabled,
+		FarmGuardianScript:               farm.FarmGuardianScript,
+		FarmGuardianCheckIntervalSeconds: farm.FarmGuardianCheckIntervalSeconds,
+	}
+
+	if fg.FarmGuardianScript == "" {
+		fg.FarmGuardianScript = "check_tcp -H HOST -p PORT"
+	}
+
+	return s.put(ctx, fg, "farms", farm.FarmName, "fg")
+}
+
+// L4BackendDetails contains all information regarding a single backend server of a L4xNAT farm.
+type L4BackendDetails struct {
+	ID        int           `json:"id"`
+	IPAddress string        `json:"ip"`
+	Port      string        `json:"port"`
+	Priority  int           `json:"priority"`
+	Weight    int           `json:"weight"`
+	Status    BackendStatus `json:"status"`
+	FarmName  string        `json:"farmname"`
+}
+
+// String returns the backend's IP, port, ID, and status.
+func (bd L4BackendDetails) String() string {
+	return fmt.Sprintf("%v:%v (ID: %v, Status: %v)", bd.IPAddress, bd.Port, bd.ID, bd.Status)
+}
+
+// Addr returns the IP address of the backend.
+func (bd L4BackendDetails) Addr() (netip.Addr, error) {
+	return parseIPAddress(bd.IPAddress)
+}
+
+type l4BackendUpdate struct {
+	IPAddress string `json:"ip"`
+	Port      string `json:"port,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	Weight    int    `json:"weight,omitempty"`
+}
+
+// CreateL4Backend creates a new backend on a L4xNAT farm.
+// The *backendPort* is optional and can be empty, using the virtual port of the farm.
+// The *weight* and *priority* are optional and can be 0, using 1 as default.
+func (s *ZapiSession) CreateL4Backend(farmName string, backendIP string, backendPort string, weight int, priority int) (*L4BackendDetails, error) {
+	return s.CreateL4BackendContext(context.Background(), farmName, backendIP, backendPort, weight, priority)
+}
+
+// CreateL4BackendContext creates a new backend on a L4xNAT farm using the provided context.
+func (s *ZapiSession) CreateL4BackendContext(ctx context.Context, farmName string, backendIP string, backendPort string, weight int, priority int) (*L4BackendDetails, error) {
+	// create the backend
+	req := l4BackendUpdate{
+		IPAddress: backendIP,
+		Port:      backendPort,
+		Priority:  priority,
+		Weight:    weight,
+	}
+
+	err := s.post(ctx, req, "farms", farmName, "backends")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	farm, err := s.GetL4FarmContext(ctx, farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if farm == nil {
+		return nil, fmt.Errorf("Farm not found: %v", farmName)
+	}
+
+	return farm.GetBackendByAddress(backendIP, backendPort)
+}
+
+// UpdateL4Backend updates a backend on a L4xNAT farm.
+func (s *ZapiSession) UpdateL4Backend(backend *L4BackendDetails) error {
+	return s.UpdateL4BackendContext(context.Background(), backend)
+}
+
+// UpdateL4BackendContext updates a backend on a L4xNAT farm using the provided context.
+func (s *ZapiSession) UpdateL4BackendContext(ctx context.Context, backend *L4BackendDetails) error {
+	req := l4BackendUpdate{
+		IPAddress: backend.IPAddress,
+		Port:      backend.Port,
+		Priority:  backend.Priority,
+		Weight:    backend.Weight,
+	}
+
+	return s.put(ctx, req, "farms", backend.FarmName, "backends", strconv.Itoa(backend.ID))
+}
+
+// DeleteL4Backend will delete an existing backend (or do nothing if backend or farm is missing)
+func (s *ZapiSession) DeleteL4Backend(farmName string, backendID int) (bool, error) {
+	return s.DeleteL4BackendContext(context.Background(), farmName, backendID)
+}
+
+// DeleteL4BackendContext will delete an existing backend (or do nothing if backend or farm is missing) using the provided context.
+func (s *ZapiSession) DeleteL4BackendContext(ctx context.Context, farmName string, backendID int) (bool, error) {
+	// retrieve farm details
+	farm, err := s.GetL4FarmContext(ctx, farmName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// farm does not exist?
+	if farm == nil {
+		return false, nil
+	}
+
+	// does the backend exist?
+	backend, err := farm.GetBackend(backendID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if backend == nil {
+		return false, nil
+	}
+
+	// delete the backend
+	return true, s.delete(ctx, "farms", farmName, "backends", strconv.Itoa(backendID))
+}
+
+// SetL4BackendMaintenance enables or disables the maintenance mode of a backend on a L4xNAT farm.
+func (s *ZapiSession) SetL4BackendMaintenance(backend *L4BackendDetails, enableMaintenance bool) error {
+	return s.SetL4BackendMaintenanceContext(context.Background(), backend, enableMaintenance)
+}
+
+// SetL4BackendMaintenanceContext enables or disables the maintenance mode of a backend on a L4xNAT farm using the provided context.
+func (s *ZapiSession) SetL4BackendMaintenanceContext(ctx context.Context, backend *L4BackendDetails, enableMaintenance bool) error {
+	cmd := backendMaintenance{
+		Action: "up",
+	}
+
+	if enableMaintenance {
+		cmd.Action = "maintenance"
+	}
+
+	return s.put(ctx, cmd, "farms", backend.FarmName, "backends", strconv.Itoa(backend.ID), "maintenance")
+}
@@ -0,0 +1,126 @@
+package zevenetlb
+
+import (
+	"testing"
+)
+
+const (
+	unitTestL4FarmName = "UNITTESTGOL4"
+)
+
+func TestRoundtripL4xNatFarm(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the farm does not exist
+	_, err := session.DeleteFarm(unitTestL4FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create the new farm
+	farm, err := session.CreateL4xNatFarm(unitTestL4FarmName, unitTestVirtualIP, 8080)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteFarm(unitTestL4FarmName)
+
+	t.Logf("New farm: %v, Status: %v", farm, farm.Status)
+
+	// update the farm
+	farm.Protocol = L4FarmProtocol_TCP
+	farm.NATType = L4FarmNATType_NAT
+	farm.Algorithm = L4FarmAlgorithm_LeastConnections
+	farm.Persistence = L4FarmPersistence_IPAddress
+	farm.PersistenceTimeoutSeconds = 60
+
+	err = session.UpdateL4Farm(farm)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err = session.GetL4Farm(unitTestL4FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.Algorithm != L4FarmAlgorithm_LeastConnections || farm.Persistence != L4FarmPersistence_IPAddress {
+		t.Fatalf("Farm was not updated: %v %v", farm.Algorithm, farm.Persistence)
+	}
+
+	// add a backend
+	backend, err := session.CreateL4Backend(farm.FarmName, "176.58.123.25", "80", 2, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backend == nil {
+		t.Fatal("Backend not found after creation")
+	}
+
+	t.Logf("Backend: %v", backend)
+
+	// update the backend
+	backend.Weight = 5
+
+	err = session.UpdateL4Backend(backend)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// enable maintenance
+	err = session.SetL4BackendMaintenance(backend, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// disable maintenance
+	err = session.SetL4BackendMaintenance(backend, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cleaning up, delete the backend
+	deleted, err := session.DeleteL4Backend(farm.FarmName, backend.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the backend to succeed, but failed")
+	}
+
+	// done, delete the farm
+	deleted, err = session.DeleteFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the farm to succeed, but failed")
+	}
+}
+
+func TestCreateFarmAsL4xNat(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	farm, err := session.CreateFarmAsL4xNat(unitTestL4FarmName, unitTestVirtualIP, 8080)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.FarmName != unitTestL4FarmName || farm.VirtualIP != unitTestVirtualIP || farm.VirtualPort != 8080 || farm.IsHTTP() {
+		t.Fatalf("Unexpected farm: %v", farm)
+	}
+}
@@ -2,12 +2,14 @@ package zevenetlb
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -131,21 +133,23 @@ func TestRoundtripHTTPFarm(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// try to connect
-	resBody, resCode, err := webGet(fmt.Sprintf("http://%v:%v", farm.VirtualIP, farm.VirtualPort))
+	// try to connect, requires a real loadbalancer
+	if isLiveTest() {
+		resBody, resCode, err := webGet(fmt.Sprintf("http://%v:%v", farm.VirtualIP, farm.VirtualPort))
 
-	if err != nil {
-		t.Fatal(err)
-	}
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	// check if the return code matches
-	if resCode != 503 {
-		t.Fatalf("Expected HTTP status code 503, but got %v", resCode)
-	}
+		// check if the return code matches
+		if resCode != 503 {
+			t.Fatalf("Expected HTTP status code 503, but got %v", resCode)
+		}
 
-	// check if the message matches
-	if !strings.Contains(resBody, farm.ErrorString503) {
-		t.Fatalf("Expected the status message to contain '%v', but got '%v'", farm.ErrorString503, resBody)
+		// check if the message matches
+		if !strings.Contains(resBody, farm.ErrorString503) {
+			t.Fatalf("Expected the status message to contain '%v', but got '%v'", farm.ErrorString503, resBody)
+		}
 	}
 
 	// add a service
@@ -173,22 +177,24 @@ func TestRoundtripHTTPFarm(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// try to connect
-	resBodyExpect, _, _ := webGet("http://176.58.123.25")
-	resBody, resCode, err = webGet(fmt.Sprintf("http://%v:%v", farm.VirtualIP, farm.VirtualPort))
+	// try to connect, requires a real loadbalancer
+	if isLiveTest() {
+		resBodyExpect, _, _ := webGet("http://176.58.123.25")
+		resBody, resCode, err := webGet(fmt.Sprintf("http://%v:%v", farm.VirtualIP, farm.VirtualPort))
 
-	if err != nil {
-		t.Fatal(err)
-	}
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	// check if the return code matches
-	if resCode != 200 {
-		t.Fatalf("Expected HTTP status code 200, but got %v", resCode)
-	}
+		// check if the return code matches
+		if resCode != 200 {
+			t.Fatalf("Expected HTTP status code 200, but got %v", resCode)
+		}
 
-	// check if the message matches
-	if !strings.Contains(resBody, resBodyExpect) {
-		t.Fatalf("Expected the status message to contain '%v', but got '%v'", resBodyExpect, resBody)
+		// check if the message matches
+		if !strings.Contains(resBody, resBodyExpect) {
+			t.Fatalf("Expected the status message to contain '%v', but got '%v'", resBodyExpect, resBody)
+		}
 	}
 
 	// enable maintenance
@@ -284,6 +290,10 @@ func TestRoundtripHTTPSFarm(t *testing.T) {
 
 	t.Logf("New farm: %v, Status: %v", farm, farm.Status)
 
+	if !farm.HasCertificate(certName) {
+		t.Fatalf("Expected certificate %v to be bound, but got %v", certName, farm.Certificates)
+	}
+
 	// update 503 message (for testing)
 	farm.ErrorString503 = fmt.Sprintf("Service unavailable ## %v ##", rand.Int63())
 
@@ -300,21 +310,23 @@ func TestRoundtripHTTPSFarm(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// try to connect
-	resBody, resCode, err := webGet(fmt.Sprintf("https://%v:%v", farm.VirtualIP, farm.VirtualPort))
+	// try to connect, requires a real loadbalancer
+	if isLiveTest() {
+		resBody, resCode, err := webGet(fmt.Sprintf("https://%v:%v", farm.VirtualIP, farm.VirtualPort))
 
-	if err != nil {
-		t.Fatal(err)
-	}
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	// check if the return code matches
-	if resCode != 503 {
-		t.Fatalf("Expected HTTP status code 503, but got %v", resCode)
-	}
+		// check if the return code matches
+		if resCode != 503 {
+			t.Fatalf("Expected HTTP status code 503, but got %v", resCode)
+		}
 
-	// check if the message matches
-	if !strings.Contains(resBody, farm.ErrorString503) {
-		t.Fatalf("Expected the status message to contain '%v', but got '%v'", farm.ErrorString503, resBody)
+		// check if the message matches
+		if !strings.Contains(resBody, farm.ErrorString503) {
+			t.Fatalf("Expected the status message to contain '%v', but got '%v'", farm.ErrorString503, resBody)
+		}
 	}
 
 	// add a service
@@ -356,22 +368,24 @@ func TestRoundtripHTTPSFarm(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// try to connect
-	resBodyExpect, _, _ := webGet("http://176.58.123.25")
-	resBody, resCode, err = webGet(fmt.Sprintf("https://%v:%v", farm.VirtualIP, farm.VirtualPort))
+	// try to connect, requires a real loadbalancer
+	if isLiveTest() {
+		resBodyExpect, _, _ := webGet("http://176.58.123.25")
+		resBody, resCode, err := webGet(fmt.Sprintf("https://%v:%v", farm.VirtualIP, farm.VirtualPort))
 
-	if err != nil {
-		t.Fatal(err)
-	}
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	// check if the return code matches
-	if resCode != 200 {
-		t.Fatalf("Expected HTTP status code 200, but got %v", resCode)
-	}
+		// check if the return code matches
+		if resCode != 200 {
+			t.Fatalf("Expected HTTP status code 200, but got %v", resCode)
+		}
 
-	// check if the message matches
-	if !strings.Contains(resBody, resBodyExpect) {
-		t.Fatalf("Expected the status message to contain '%v', but got '%v'", resBodyExpect, resBody)
+		// check if the message matches
+		if !strings.Contains(resBody, resBodyExpect) {
+			t.Fatalf("Expected the status message to contain '%v', but got '%v'", resBodyExpect, resBody)
+		}
 	}
 
 	// cleaning up, delete the backend
@@ -407,3 +421,79 @@ func TestRoundtripHTTPSFarm(t *testing.T) {
 		t.Fatal("Expected deleting to succeed, but failed")
 	}
 }
+
+func TestFarmCertificates(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the farm does not exist
+	_, err := session.DeleteFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload two certificates
+	var certNames []string
+
+	for _, name := range []string{"unittestgo1.pem", "unittestgo2.pem"} {
+		cert, key := createTestCertificate(t, "unittest.example.com", time.Now().Add(24*time.Hour))
+
+		_, err = session.UploadX509Certificate(name, []*x509.Certificate{cert}, key)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer session.DeleteCertificate(name)
+
+		certNames = append(certNames, name)
+	}
+
+	// create the new farm
+	farm, err := session.CreateFarmAsHTTPS(unitTestFarmName, unitTestVirtualIP, 0, certNames[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteFarm(unitTestFarmName)
+
+	if len(farm.Certificates) != 1 || farm.Certificates[0].Filename != certNames[0] {
+		t.Fatalf("Unexpected certificates: %v", farm.Certificates)
+	}
+
+	// add the second certificate
+	err = session.AddFarmCertificate(farm.FarmName, certNames[1])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reverse the order
+	err = session.ReorderFarmCertificates(farm.FarmName, []string{certNames[1], certNames[0]})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err = session.GetFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(farm.Certificates) != 2 || farm.Certificates[0].Filename != certNames[1] || farm.Certificates[1].Filename != certNames[0] {
+		t.Fatalf("Unexpected certificate order: %v", farm.Certificates)
+	}
+
+	// remove the first certificate
+	removed, err := session.RemoveFarmCertificate(farm.FarmName, certNames[1])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !removed {
+		t.Fatal("Expected removing the certificate to succeed, but failed")
+	}
+}
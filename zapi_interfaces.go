@@ -1,9 +1,58 @@
 package zevenetlb
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
 )
 
+// InterfaceStatus defines whether an interface is up or down.
+type InterfaceStatus string
+
+const (
+	// InterfaceStatus_Up means the interface is up.
+	InterfaceStatus_Up InterfaceStatus = "up"
+
+	// InterfaceStatus_Down means the interface is down. Use *SetInterfaceState()* to bring it up.
+	InterfaceStatus_Down InterfaceStatus = "down"
+)
+
+// InterfaceType defines the kind of a network interface.
+type InterfaceType string
+
+const (
+	// InterfaceType_NIC is a physical network interface, e.g. "eth0".
+	InterfaceType_NIC InterfaceType = "nic"
+
+	// InterfaceType_VLAN is a tagged VLAN interface on top of a NIC, e.g. "eth0.100".
+	InterfaceType_VLAN InterfaceType = "vlan"
+
+	// InterfaceType_Bond is a bonding interface on top of multiple NICs, e.g. "bond0".
+	InterfaceType_Bond InterfaceType = "bonding"
+
+	// InterfaceType_Virtual is an additional IP address on another interface, e.g. "eth0:web".
+	InterfaceType_Virtual InterfaceType = "virtual"
+)
+
+type interfaceAction struct {
+	Action string `json:"action"`
+}
+
+// SetInterfaceState brings an interface up or down.
+func (s *ZapiSession) SetInterfaceState(interfaceType InterfaceType, interfaceName string, status InterfaceStatus) error {
+	return s.SetInterfaceStateContext(context.Background(), interfaceType, interfaceName, status)
+}
+
+// SetInterfaceStateContext brings an interface up or down using the provided context.
+func (s *ZapiSession) SetInterfaceStateContext(ctx context.Context, interfaceType InterfaceType, interfaceName string, status InterfaceStatus) error {
+	if status != InterfaceStatus_Up && status != InterfaceStatus_Down {
+		return fmt.Errorf("Invalid interface status %v, expected up or down", status)
+	}
+
+	return s.post(ctx, interfaceAction{Action: string(status)}, "interfaces", string(interfaceType), interfaceName, "actions")
+}
+
 //
 // Network Interfaces
 //
@@ -16,20 +65,35 @@ type nicListResponse struct {
 // NetworkInterfaceInfo contains the list of all available NICs.
 // See https://www.zevenet.com/zapidoc_ce_v3.1/#list-nic-interfaces
 type NetworkInterfaceInfo struct {
-	IP      string `json:"ip"`
-	HasVlan string `json:"has_vlan"`
-	Netmask string `json:"netmask"`
-	Gateway string `json:"gateway"`
-	MAC     string `json:"mac"`
-	Name    string `json:"name"`
-	Status  string `json:"status"`
+	IP      string          `json:"ip"`
+	HasVlan string          `json:"has_vlan"`
+	Netmask string          `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	MAC     string          `json:"mac"`
+	Name    string          `json:"name"`
+	Status  InterfaceStatus `json:"status"`
+}
+
+// Addr returns the IP address of the NIC.
+func (ni *NetworkInterfaceInfo) Addr() (netip.Addr, error) {
+	return parseIPAddress(ni.IP)
+}
+
+// Prefix returns the IP address and the prefix length of the NIC, e.g. "10.0.0.10/24" or "2001:db8::10/64".
+func (ni *NetworkInterfaceInfo) Prefix() (netip.Prefix, error) {
+	return parseIPPrefix(ni.IP, ni.Netmask)
 }
 
 // GetAllNetworkInterfaces returns list os all available NICs.
 func (s *ZapiSession) GetAllNetworkInterfaces() ([]NetworkInterfaceInfo, error) {
+	return s.GetAllNetworkInterfacesContext(context.Background())
+}
+
+// GetAllNetworkInterfacesContext returns list os all available NICs using the provided context.
+func (s *ZapiSession) GetAllNetworkInterfacesContext(ctx context.Context) ([]NetworkInterfaceInfo, error) {
 	var result *nicListResponse
 
-	err := s.getForEntity(&result, "interfaces", "nic")
+	err := s.getForEntity(ctx, &result, "interfaces", "nic")
 
 	if err != nil {
 		return nil, err
@@ -38,6 +102,74 @@ func (s *ZapiSession) GetAllNetworkInterfaces() ([]NetworkInterfaceInfo, error)
 	return result.Interfaces, nil
 }
 
+type nicDetailsResponse struct {
+	Description string               `json:"description"`
+	Interface   NetworkInterfaceInfo `json:"interface"`
+}
+
+// GetNetworkInterface returns details on a specific NIC, or *nil* if not found.
+func (s *ZapiSession) GetNetworkInterface(nicName string) (*NetworkInterfaceInfo, error) {
+	return s.GetNetworkInterfaceContext(context.Background(), nicName)
+}
+
+// GetNetworkInterfaceContext returns details on a specific NIC using the provided context.
+func (s *ZapiSession) GetNetworkInterfaceContext(ctx context.Context, nicName string) (*NetworkInterfaceInfo, error) {
+	var result *nicDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "nic", nicName)
+
+	if err != nil {
+		// interface not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Interface, nil
+}
+
+type nicUpdate struct {
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// UpdateNetworkInterface updates the IP address, netmask, and gateway of a NIC.
+// For an IPv6 address, the netmask is the prefix length, e.g. "64".
+func (s *ZapiSession) UpdateNetworkInterface(nic *NetworkInterfaceInfo) error {
+	return s.UpdateNetworkInterfaceContext(context.Background(), nic)
+}
+
+// UpdateNetworkInterfaceContext updates the IP address, netmask, and gateway of a NIC using the provided context.
+func (s *ZapiSession) UpdateNetworkInterfaceContext(ctx context.Context, nic *NetworkInterfaceInfo) error {
+	req := nicUpdate{
+		IP:      nic.IP,
+		Netmask: nic.Netmask,
+		Gateway: nic.Gateway,
+	}
+
+	return s.put(ctx, req, "interfaces", "nic", nic.Name)
+}
+
+// UpdateNetworkInterfaceIPv6 configures the IPv6 address and prefix length of a NIC, e.g. "2001:db8::10/64".
+// The *gateway* is optional and can be the zero value.
+func (s *ZapiSession) UpdateNetworkInterfaceIPv6(nicName string, prefix netip.Prefix, gateway netip.Addr) error {
+	return s.UpdateNetworkInterfaceIPv6Context(context.Background(), nicName, prefix, gateway)
+}
+
+// UpdateNetworkInterfaceIPv6Context configures the IPv6 address and prefix length of a NIC using the provided context.
+func (s *ZapiSession) UpdateNetworkInterfaceIPv6Context(ctx context.Context, nicName string, prefix netip.Prefix, gateway netip.Addr) error {
+	ip, netmask, gw, err := ipv6Config(prefix, gateway)
+
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateNetworkInterfaceContext(ctx, &NetworkInterfaceInfo{Name: nicName, IP: ip, Netmask: netmask, Gateway: gw})
+}
+
 //
 // Virtual Interfaces
 //
@@ -50,20 +182,30 @@ type virtualInterfaceListResponse struct {
 // VirtualInterfaceInfo contains the list of all available virtual Interfaces.
 // See https://www.zevenet.com/zapidoc_ce_v3.1/#list-virtual-interfaces
 type VirtualInterfaceInfo struct {
-	IP      string `json:"ip"`
-	Parent  string `json:"parent"`
-	Netmask string `json:"netmask"`
-	Gateway string `json:"gateway"`
-	MAC     string `json:"mac"`
-	Name    string `json:"name"`
-	Status  string `json:"status"`
+	IP      string          `json:"ip"`
+	Parent  string          `json:"parent"`
+	Netmask string          `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	MAC     string          `json:"mac"`
+	Name    string          `json:"name"`
+	Status  InterfaceStatus `json:"status"`
+}
+
+// Addr returns the IP address of the virtual interface.
+func (vi *VirtualInterfaceInfo) Addr() (netip.Addr, error) {
+	return parseIPAddress(vi.IP)
 }
 
 // GetAllVirtualInterfaces returns list os all available NICs.
 func (s *ZapiSession) GetAllVirtualInterfaces() ([]VirtualInterfaceInfo, error) {
+	return s.GetAllVirtualInterfacesContext(context.Background())
+}
+
+// GetAllVirtualInterfacesContext returns list os all available NICs using the provided context.
+func (s *ZapiSession) GetAllVirtualInterfacesContext(ctx context.Context) ([]VirtualInterfaceInfo, error) {
 	var result *virtualInterfaceListResponse
 
-	err := s.getForEntity(&result, "interfaces", "virtual")
+	err := s.getForEntity(ctx, &result, "interfaces", "virtual")
 
 	if err != nil {
 		return nil, err
@@ -80,26 +222,39 @@ type virtualInterfaceDetailsResponse struct {
 // VirtualInterfaceDetails contains all information regarding a virtual Interface.
 // See https://www.zevenet.com/zapidoc_ce_v3.1/#retrieve-virtual-interface
 type VirtualInterfaceDetails struct {
-	IP      string `json:"ip"`
-	Netmask string `json:"netmask"`
-	Gateway string `json:"gateway"`
-	MAC     string `json:"mac"`
-	Name    string `json:"name"`
-	Status  string `json:"status"`
+	IP      string          `json:"ip"`
+	Netmask string          `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	MAC     string          `json:"mac"`
+	Name    string          `json:"name"`
+	Status  InterfaceStatus `json:"status"`
+}
+
+// Addr returns the IP address of the virtual interface.
+func (vd *VirtualInterfaceDetails) Addr() (netip.Addr, error) {
+	return parseIPAddress(vd.IP)
+}
+
+// Prefix returns the IP address and the prefix length of the virtual interface, e.g. "10.0.0.10/24" or "2001:db8::10/64".
+func (vd *VirtualInterfaceDetails) Prefix() (netip.Prefix, error) {
+	return parseIPPrefix(vd.IP, vd.Netmask)
 }
 
 // GetVirtualInterface returns details on a specific virtual Interface.
 func (s *ZapiSession) GetVirtualInterface(virtualInterfaceName string) (*VirtualInterfaceDetails, error) {
+	return s.GetVirtualInterfaceContext(context.Background(), virtualInterfaceName)
+}
+
+// GetVirtualInterfaceContext returns details on a specific virtual Interface using the provided context.
+func (s *ZapiSession) GetVirtualInterfaceContext(ctx context.Context, virtualInterfaceName string) (*VirtualInterfaceDetails, error) {
 	var result *virtualInterfaceDetailsResponse
 
-	err := s.getForEntity(&result, "interfaces", "virtual", virtualInterfaceName)
+	err := s.getForEntity(ctx, &result, "interfaces", "virtual", virtualInterfaceName)
 
 	if err != nil {
 		// virtualInterface not found?
-		if v, ok := err.(RequestError); ok {
-			if strings.Contains(v.Message, "not found") {
-				return nil, nil
-			}
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
 		}
 
 		return nil, err
@@ -110,8 +265,13 @@ func (s *ZapiSession) GetVirtualInterface(virtualInterfaceName string) (*Virtual
 
 // DeleteVirtualInterface will delete an existing virtual Interface (or do nothing if missing)
 func (s *ZapiSession) DeleteVirtualInterface(virtualInterfaceName string) (bool, error) {
+	return s.DeleteVirtualInterfaceContext(context.Background(), virtualInterfaceName)
+}
+
+// DeleteVirtualInterfaceContext will delete an existing virtual Interface (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteVirtualInterfaceContext(ctx context.Context, virtualInterfaceName string) (bool, error) {
 	// retrieve virtualInterface details
-	virtualInterface, err := s.GetVirtualInterface(virtualInterfaceName)
+	virtualInterface, err := s.GetVirtualInterfaceContext(ctx, virtualInterfaceName)
 
 	if err != nil {
 		return false, err
@@ -123,7 +283,7 @@ func (s *ZapiSession) DeleteVirtualInterface(virtualInterfaceName string) (bool,
 	}
 
 	// delete the farm
-	return true, s.delete("interfaces", "virtual", virtualInterfaceName)
+	return true, s.delete(ctx, "interfaces", "virtual", virtualInterfaceName)
 }
 
 type virtualInterfaceCreate struct {
@@ -133,18 +293,26 @@ type virtualInterfaceCreate struct {
 
 // CreateVirtualInterface creates a new virtual Interface.
 func (s *ZapiSession) CreateVirtualInterface(virtualInterfaceName string, virtualIP string) (*VirtualInterfaceDetails, error) {
+	return s.CreateVirtualInterfaceContext(context.Background(), virtualInterfaceName, virtualIP)
+}
+
+// CreateVirtualInterfaceContext creates a new virtual Interface using the provided context.
+func (s *ZapiSession) CreateVirtualInterfaceContext(ctx context.Context, virtualInterfaceName string, virtualIP string) (*VirtualInterfaceDetails, error) {
+	if _, err := parseIPAddress(virtualIP); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrValidation)
+	}
 
 	req := virtualInterfaceCreate{
 		IP:   virtualIP,
 		Name: virtualInterfaceName,
 	}
 
-	err := s.post(req, "interfaces", "virtual")
+	err := s.post(ctx, req, "interfaces", "virtual")
 
 	if err != nil {
 		return nil, err
 	}
 
 	// retrieve status
-	return s.GetVirtualInterface(virtualInterfaceName)
+	return s.GetVirtualInterfaceContext(ctx, virtualInterfaceName)
 }
@@ -0,0 +1,297 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+//
+// Bonding Interfaces
+//
+
+// BondMode defines how a bonding interface distributes the traffic on its slaves.
+type BondMode string
+
+const (
+	// BondMode_RoundRobin transmits packets on each slave in turn (mode 0).
+	BondMode_RoundRobin BondMode = "balance-rr"
+
+	// BondMode_ActiveBackup uses only one slave, another slave takes over on failure (mode 1).
+	BondMode_ActiveBackup BondMode = "active-backup"
+
+	// BondMode_XOR selects the slave by a hash of the MAC addresses (mode 2).
+	BondMode_XOR BondMode = "balance-xor"
+
+	// BondMode_Broadcast transmits all packets on all slaves (mode 3).
+	BondMode_Broadcast BondMode = "broadcast"
+
+	// BondMode_LACP aggregates the slaves using IEEE 802.3ad dynamic link aggregation (mode 4). The switch has to support LACP.
+	BondMode_LACP BondMode = "802.3ad"
+
+	// BondMode_TransmitLoadBalancing distributes the outgoing traffic by the load of the slaves (mode 5).
+	BondMode_TransmitLoadBalancing BondMode = "balance-tlb"
+
+	// BondMode_AdaptiveLoadBalancing distributes the outgoing and incoming traffic by the load of the slaves (mode 6).
+	BondMode_AdaptiveLoadBalancing BondMode = "balance-alb"
+)
+
+type bondInterfaceListResponse struct {
+	Description string                 `json:"description"`
+	Interfaces  []BondInterfaceDetails `json:"interfaces"`
+}
+
+type bondInterfaceDetailsResponse struct {
+	Description string               `json:"description"`
+	Interface   BondInterfaceDetails `json:"interface"`
+}
+
+// BondSlave is a NIC that is part of a bonding interface.
+type BondSlave struct {
+	Name string `json:"name"`
+}
+
+// BondInterfaceDetails contains all information regarding a bonding interface.
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#bonding-interface
+type BondInterfaceDetails struct {
+	Name    string          `json:"name"`
+	Mode    BondMode        `json:"mode"`
+	Slaves  []BondSlave     `json:"slaves"`
+	IP      string          `json:"ip"`
+	Netmask string          `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	MAC     string          `json:"mac"`
+	Status  InterfaceStatus `json:"status"`
+}
+
+// String returns the bonding interface's name and mode.
+func (bd *BondInterfaceDetails) String() string {
+	return fmt.Sprintf("%v (%v)", bd.Name, bd.Mode)
+}
+
+// SlaveNames returns the names of the slave NICs.
+func (bd *BondInterfaceDetails) SlaveNames() []string {
+	var names []string
+
+	for _, s := range bd.Slaves {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+// HasSlave checks if the NIC is a slave of the bonding interface.
+func (bd *BondInterfaceDetails) HasSlave(nicName string) bool {
+	for _, s := range bd.Slaves {
+		if s.Name == nicName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Addr returns the IP address of the bonding interface.
+func (bd *BondInterfaceDetails) Addr() (netip.Addr, error) {
+	return parseIPAddress(bd.IP)
+}
+
+// Prefix returns the IP address and the prefix length of the bonding interface, e.g. "10.0.0.10/24" or "2001:db8::10/64".
+func (bd *BondInterfaceDetails) Prefix() (netip.Prefix, error) {
+	return parseIPPrefix(bd.IP, bd.Netmask)
+}
+
+// GetAllBondInterfaces returns list of all bonding interfaces.
+func (s *ZapiSession) GetAllBondInterfaces() ([]BondInterfaceDetails, error) {
+	return s.GetAllBondInterfacesContext(context.Background())
+}
+
+// GetAllBondInterfacesContext returns list of all bonding interfaces using the provided context.
+func (s *ZapiSession) GetAllBondInterfacesContext(ctx context.Context) ([]BondInterfaceDetails, error) {
+	var result *bondInterfaceListResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "bonding")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Interfaces, nil
+}
+
+// GetBondInterface returns details on a specific bonding interface, or *nil* if not found.
+func (s *ZapiSession) GetBondInterface(bondInterfaceName string) (*BondInterfaceDetails, error) {
+	return s.GetBondInterfaceContext(context.Background(), bondInterfaceName)
+}
+
+// GetBondInterfaceContext returns details on a specific bonding interface using the provided context.
+func (s *ZapiSession) GetBondInterfaceContext(ctx context.Context, bondInterfaceName string) (*BondInterfaceDetails, error) {
+	var result *bondInterfaceDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "bonding", bondInterfaceName)
+
+	if err != nil {
+		// interface not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Interface, nil
+}
+
+type bondInterfaceCreate struct {
+	Name   string   `json:"name"`
+	Mode   BondMode `json:"mode"`
+	Slaves []string `json:"slaves"`
+}
+
+// CreateBondInterface creates a new bonding interface, e.g. "bond0", on top of the slave NICs.
+// Use *UpdateBondInterface()* to set its IP address afterwards.
+func (s *ZapiSession) CreateBondInterface(bondInterfaceName string, mode BondMode, slaveNames []string) (*BondInterfaceDetails, error) {
+	return s.CreateBondInterfaceContext(context.Background(), bondInterfaceName, mode, slaveNames)
+}
+
+// CreateBondInterfaceContext creates a new bonding interface on top of the slave NICs using the provided context.
+func (s *ZapiSession) CreateBondInterfaceContext(ctx context.Context, bondInterfaceName string, mode BondMode, slaveNames []string) (*BondInterfaceDetails, error) {
+	if len(slaveNames) <= 0 {
+		return nil, errors.New("At least one slave is required")
+	}
+
+	req := bondInterfaceCreate{
+		Name:   bondInterfaceName,
+		Mode:   mode,
+		Slaves: slaveNames,
+	}
+
+	err := s.post(ctx, req, "interfaces", "bonding")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetBondInterfaceContext(ctx, bondInterfaceName)
+}
+
+type bondInterfaceUpdate struct {
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// UpdateBondInterface updates the IP address, netmask, and gateway of a bonding interface.
+// The mode and slaves are not changed, use *AddBondSlave()* and *RemoveBondSlave()* instead.
+func (s *ZapiSession) UpdateBondInterface(bond *BondInterfaceDetails) error {
+	return s.UpdateBondInterfaceContext(context.Background(), bond)
+}
+
+// UpdateBondInterfaceContext updates the IP address, netmask, and gateway of a bonding interface using the provided context.
+func (s *ZapiSession) UpdateBondInterfaceContext(ctx context.Context, bond *BondInterfaceDetails) error {
+	req := bondInterfaceUpdate{
+		IP:      bond.IP,
+		Netmask: bond.Netmask,
+		Gateway: bond.Gateway,
+	}
+
+	return s.put(ctx, req, "interfaces", "bonding", bond.Name)
+}
+
+// UpdateBondInterfaceIPv6 configures the IPv6 address and prefix length of a bonding interface, e.g. "2001:db8::10/64".
+// The *gateway* is optional and can be the zero value.
+func (s *ZapiSession) UpdateBondInterfaceIPv6(bondInterfaceName string, prefix netip.Prefix, gateway netip.Addr) error {
+	return s.UpdateBondInterfaceIPv6Context(context.Background(), bondInterfaceName, prefix, gateway)
+}
+
+// UpdateBondInterfaceIPv6Context configures the IPv6 address and prefix length of a bonding interface using the provided context.
+func (s *ZapiSession) UpdateBondInterfaceIPv6Context(ctx context.Context, bondInterfaceName string, prefix netip.Prefix, gateway netip.Addr) error {
+	ip, netmask, gw, err := ipv6Config(prefix, gateway)
+
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateBondInterfaceContext(ctx, &BondInterfaceDetails{Name: bondInterfaceName, IP: ip, Netmask: netmask, Gateway: gw})
+}
+
+// DeleteBondInterface will delete an existing bonding interface (or do nothing if missing)
+func (s *ZapiSession) DeleteBondInterface(bondInterfaceName string) (bool, error) {
+	return s.DeleteBondInterfaceContext(context.Background(), bondInterfaceName)
+}
+
+// DeleteBondInterfaceContext will delete an existing bonding interface (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteBondInterfaceContext(ctx context.Context, bondInterfaceName string) (bool, error) {
+	// retrieve interface details
+	bond, err := s.GetBondInterfaceContext(ctx, bondInterfaceName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// interface does not exist?
+	if bond == nil {
+		return false, nil
+	}
+
+	// delete the interface
+	return true, s.delete(ctx, "interfaces", "bonding", bondInterfaceName)
+}
+
+type bondSlaveCreate struct {
+	Name string `json:"name"`
+}
+
+// AddBondSlave adds a NIC to a bonding interface.
+func (s *ZapiSession) AddBondSlave(bondInterfaceName string, nicName string) error {
+	return s.AddBondSlaveContext(context.Background(), bondInterfaceName, nicName)
+}
+
+// AddBondSlaveContext adds a NIC to a bonding interface using the provided context.
+func (s *ZapiSession) AddBondSlaveContext(ctx context.Context, bondInterfaceName string, nicName string) error {
+	return s.post(ctx, bondSlaveCreate{Name: nicName}, "interfaces", "bonding", bondInterfaceName, "slaves")
+}
+
+// RemoveBondSlave removes a NIC from a bonding interface (or does nothing if it is not a slave).
+func (s *ZapiSession) RemoveBondSlave(bondInterfaceName string, nicName string) (bool, error) {
+	return s.RemoveBondSlaveContext(context.Background(), bondInterfaceName, nicName)
+}
+
+// RemoveBondSlaveContext removes a NIC from a bonding interface (or does nothing if it is not a slave) using the provided context.
+func (s *ZapiSession) RemoveBondSlaveContext(ctx context.Context, bondInterfaceName string, nicName string) (bool, error) {
+	// retrieve interface details
+	bond, err := s.GetBondInterfaceContext(ctx, bondInterfaceName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// not a slave?
+	if bond == nil || !bond.HasSlave(nicName) {
+		return false, nil
+	}
+
+	return true, s.delete(ctx, "interfaces", "bonding", bondInterfaceName, "slaves", nicName)
+}
+
+// StartBondInterface brings a bonding interface up.
+func (s *ZapiSession) StartBondInterface(bondInterfaceName string) error {
+	return s.StartBondInterfaceContext(context.Background(), bondInterfaceName)
+}
+
+// StartBondInterfaceContext brings a bonding interface up using the provided context.
+func (s *ZapiSession) StartBondInterfaceContext(ctx context.Context, bondInterfaceName string) error {
+	return s.SetInterfaceStateContext(ctx, InterfaceType_Bond, bondInterfaceName, InterfaceStatus_Up)
+}
+
+// StopBondInterface brings a bonding interface down.
+func (s *ZapiSession) StopBondInterface(bondInterfaceName string) error {
+	return s.StopBondInterfaceContext(context.Background(), bondInterfaceName)
+}
+
+// StopBondInterfaceContext brings a bonding interface down using the provided context.
+func (s *ZapiSession) StopBondInterfaceContext(ctx context.Context, bondInterfaceName string) error {
+	return s.SetInterfaceStateContext(ctx, InterfaceType_Bond, bondInterfaceName, InterfaceStatus_Down)
+}
@@ -0,0 +1,118 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+//
+// Floating Interfaces
+//
+
+type floatingInterfaceListResponse struct {
+	Description string                     `json:"description"`
+	Params      []FloatingInterfaceDetails `json:"params"`
+}
+
+type floatingInterfaceDetailsResponse struct {
+	Description string                   `json:"description"`
+	Params      FloatingInterfaceDetails `json:"params"`
+}
+
+// FloatingInterfaceDetails maps a NIC to the floating IP address used as source address for outbound NAT,
+// which moves to the active node of a cluster. Only available in the Enterprise Edition.
+// See https://www.zevenet.com/zapidoc_ee_v3.1/#floating-interfaces
+type FloatingInterfaceDetails struct {
+	Interface  string `json:"interface"`
+	FloatingIP string `json:"floating_ip"`
+}
+
+// String returns the NIC and the floating IP address.
+func (fd *FloatingInterfaceDetails) String() string {
+	return fmt.Sprintf("%v (%v)", fd.Interface, fd.FloatingIP)
+}
+
+// FloatingAddr returns the floating IP address.
+func (fd *FloatingInterfaceDetails) FloatingAddr() (netip.Addr, error) {
+	return parseIPAddress(fd.FloatingIP)
+}
+
+// GetAllFloatingInterfaces returns list of all floating interfaces.
+func (s *ZapiSession) GetAllFloatingInterfaces() ([]FloatingInterfaceDetails, error) {
+	return s.GetAllFloatingInterfacesContext(context.Background())
+}
+
+// GetAllFloatingInterfacesContext returns list of all floating interfaces using the provided context.
+func (s *ZapiSession) GetAllFloatingInterfacesContext(ctx context.Context) ([]FloatingInterfaceDetails, error) {
+	var result *floatingInterfaceListResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "floating")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Params, nil
+}
+
+// GetFloatingInterface returns the floating IP address of a NIC, or *nil* if not configured.
+func (s *ZapiSession) GetFloatingInterface(nicName string) (*FloatingInterfaceDetails, error) {
+	return s.GetFloatingInterfaceContext(context.Background(), nicName)
+}
+
+// GetFloatingInterfaceContext returns the floating IP address of a NIC using the provided context.
+func (s *ZapiSession) GetFloatingInterfaceContext(ctx context.Context, nicName string) (*FloatingInterfaceDetails, error) {
+	var result *floatingInterfaceDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "floating", nicName)
+
+	if err != nil {
+		// interface not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Params, nil
+}
+
+type floatingInterfaceUpdate struct {
+	FloatingIP string `json:"floating_ip"`
+}
+
+// SetFloatingInterface sets the floating IP address of a NIC. The address has to be the IP address of a virtual interface on the NIC.
+func (s *ZapiSession) SetFloatingInterface(nicName string, floatingIP string) error {
+	return s.SetFloatingInterfaceContext(context.Background(), nicName, floatingIP)
+}
+
+// SetFloatingInterfaceContext sets the floating IP address of a NIC using the provided context.
+func (s *ZapiSession) SetFloatingInterfaceContext(ctx context.Context, nicName string, floatingIP string) error {
+	return s.put(ctx, floatingInterfaceUpdate{FloatingIP: floatingIP}, "interfaces", "floating", nicName)
+}
+
+// DeleteFloatingInterface will remove the floating IP address of a NIC (or do nothing if not configured)
+func (s *ZapiSession) DeleteFloatingInterface(nicName string) (bool, error) {
+	return s.DeleteFloatingInterfaceContext(context.Background(), nicName)
+}
+
+// DeleteFloatingInterfaceContext will remove the floating IP address of a NIC (or do nothing if not configured) using the provided context.
+func (s *ZapiSession) DeleteFloatingInterfaceContext(ctx context.Context, nicName string) (bool, error) {
+	// retrieve floating details
+	floating, err := s.GetFloatingInterfaceContext(ctx, nicName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// not configured?
+	if floating == nil {
+		return false, nil
+	}
+
+	// delete the floating address
+	return true, s.delete(ctx, "interfaces", "floating", nicName)
+}
@@ -1,6 +1,8 @@
 package zevenetlb
 
 import (
+	"errors"
+	"net/netip"
 	"runtime"
 	"testing"
 
@@ -10,6 +12,14 @@ import (
 const (
 	unitTestVirtualInterfaceName = "eth0:unittest"
 	unitTestVirtualInterfaceIP   = "10.209.0.31"
+
+	unitTestVLANParent  = "eth0"
+	unitTestVLANTag     = 209
+	unitTestVLANIP      = "10.209.1.10"
+	unitTestVLANNetmask = "255.255.255.0"
+
+	unitTestBondName = "bond0"
+	unitTestBondIP   = "10.209.2.10"
 )
 
 func TestGetAllNetworkInterfaces(t *testing.T) {
@@ -26,6 +36,82 @@ func TestGetAllNetworkInterfaces(t *testing.T) {
 	}
 }
 
+func TestRoundtripNetworkInterface(t *testing.T) {
+	// reconfiguring the NICs of a real loadbalancer might cut it off, always use the fake
+	session := createFakeTestSession(t)
+
+	nic, err := session.GetNetworkInterface("eth1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nic == nil || nic.IP != "" || nic.Status != InterfaceStatus_Down {
+		t.Fatalf("Unexpected NIC: %+v", nic)
+	}
+
+	// configure an IPv6 address and bring it up
+	nic.IP = "2001:db8::10"
+	nic.Netmask = "64"
+	nic.Gateway = "2001:db8::1"
+
+	err = session.UpdateNetworkInterface(nic)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = session.SetInterfaceState(InterfaceType_NIC, "eth1", InterfaceStatus_Up)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nic, err = session.GetNetworkInterface("eth1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nic.IP != "2001:db8::10" || nic.Netmask != "64" || nic.Status != InterfaceStatus_Up {
+		t.Fatalf("Unexpected NIC: %+v", nic)
+	}
+
+	// virtual interfaces can be brought down, too
+	err = session.SetInterfaceState(InterfaceType_Virtual, "eth0:sample", InterfaceStatus_Down)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vint, err := session.GetVirtualInterface("eth0:sample")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vint.Status != InterfaceStatus_Down {
+		t.Fatalf("Unexpected virtual interface: %+v", vint)
+	}
+
+	// unknown states and interfaces
+	err = session.SetInterfaceState(InterfaceType_NIC, "eth1", "restart")
+
+	if err == nil {
+		t.Fatal("Expected an invalid state to fail")
+	}
+
+	nic, err = session.GetNetworkInterface("eth9")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nic != nil {
+		t.Fatalf("Expected no NIC: %+v", nic)
+	}
+}
+
 func TestGetAllVirtualInterfaces(t *testing.T) {
 	session := createTestSession(t)
 
@@ -61,30 +147,321 @@ func TestRoundtripVirtualInterface(t *testing.T) {
 
 	t.Logf("New Int: %v, Status: %v", vint.Name, vint.Status)
 
-	// try to connect
-	pinger, err := ping.NewPinger(vint.IP)
+	// try to connect, requires a real loadbalancer
+	if isLiveTest() {
+		pinger, err := ping.NewPinger(vint.IP)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if runtime.GOOS == "windows" {
+			pinger.SetPrivileged(true)
+		}
+
+		pinger.Count = 3
+		pinger.Run() // blocks until finished
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// done, delete the virtualInterface
+	deleted, err := session.DeleteVirtualInterface(vint.Name)
+
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if runtime.GOOS == "windows" {
-		pinger.SetPrivileged(true)
+	if !deleted {
+		t.Fatal("Expected deleting the virtual Interface to succeed, but failed")
 	}
+}
 
-	pinger.Count = 3
-	pinger.Run() // blocks until finished
+func TestRoundtripVLANInterface(t *testing.T) {
+	session := createTestSession(t)
+
+	name := VLANInterfaceName(unitTestVLANParent, unitTestVLANTag)
+
+	// ensure the VLAN interface does not exist
+	_, err := session.DeleteVLANInterface(name)
 
 	if err != nil {
 		t.Fatal(err)
 	}
-	// done, delete the virtualInterface
-	deleted, err := session.DeleteVirtualInterface(vint.Name)
+
+	// create the new VLAN interface
+	vlan, err := session.CreateVLANInterface(unitTestVLANParent, unitTestVLANTag, unitTestVLANIP, unitTestVLANNetmask, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteVLANInterface(name)
+
+	t.Logf("New VLAN: %v, Status: %v", vlan, vlan.Status)
+
+	if vlan.Name != name || vlan.Parent != unitTestVLANParent || vlan.Tag() != unitTestVLANTag || vlan.IP != unitTestVLANIP {
+		t.Fatalf("Unexpected VLAN interface: %+v", vlan)
+	}
+
+	// update the gateway
+	vlan.Gateway = "10.209.1.1"
+
+	err = session.UpdateVLANInterface(vlan)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring it down and up again
+	err = session.StopVLANInterface(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vlan, err = session.GetVLANInterface(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vlan.Gateway != "10.209.1.1" || vlan.Status != "down" {
+		t.Fatalf("Unexpected VLAN interface: %+v", vlan)
+	}
+
+	err = session.StartVLANInterface(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vlans, err := session.GetAllVLANInterfaces()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vlans) <= 0 {
+		t.Fatal("No VLAN interfaces returned")
+	}
+
+	// done, delete the VLAN interface
+	deleted, err := session.DeleteVLANInterface(name)
 
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	if !deleted {
-		t.Fatal("Expected deleting the virtual Interface to succeed, but failed")
+		t.Fatal("Expected deleting the VLAN interface to succeed, but failed")
+	}
+}
+
+func TestRoundtripBondInterface(t *testing.T) {
+	// bonding the NICs of a real loadbalancer might cut it off, always use the fake
+	session := createFakeTestSession(t)
+
+	// create the new bonding interface
+	bond, err := session.CreateBondInterface(unitTestBondName, BondMode_LACP, []string{"eth1"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("New Bond: %v, Status: %v", bond, bond.Status)
+
+	if bond.Name != unitTestBondName || bond.Mode != BondMode_LACP || !bond.HasSlave("eth1") {
+		t.Fatalf("Unexpected bonding interface: %+v", bond)
+	}
+
+	// configured NICs cannot be slaves
+	err = session.AddBondSlave(unitTestBondName, "eth0")
+
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected adding a configured NIC to fail, got %v", err)
+	}
+
+	// add and remove slaves
+	err = session.AddBondSlave(unitTestBondName, "eth2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := session.RemoveBondSlave(unitTestBondName, "eth1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !removed {
+		t.Fatal("Expected removing the slave to succeed, but failed")
+	}
+
+	// set the IP address
+	bond.IP = unitTestBondIP
+	bond.Netmask = "255.255.255.0"
+
+	err = session.UpdateBondInterface(bond)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring it down
+	err = session.StopBondInterface(unitTestBondName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bond, err = session.GetBondInterface(unitTestBondName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bond.IP != unitTestBondIP || bond.Status != "down" || len(bond.Slaves) != 1 || bond.SlaveNames()[0] != "eth2" {
+		t.Fatalf("Unexpected bonding interface: %+v", bond)
+	}
+
+	err = session.StartBondInterface(unitTestBondName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bonds, err := session.GetAllBondInterfaces()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bonds) != 1 || bonds[0].Status != "up" {
+		t.Fatalf("Unexpected bonding interfaces: %+v", bonds)
+	}
+
+	// done, delete the bonding interface
+	deleted, err := session.DeleteBondInterface(unitTestBondName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the bonding interface to succeed, but failed")
+	}
+}
+
+func TestRoundtripFloatingInterface(t *testing.T) {
+	// floating interfaces require the Enterprise Edition, always use the fake
+	session := createFakeTestSession(t)
+
+	// the address has to belong to a virtual interface
+	err := session.SetFloatingInterface("eth0", "10.209.0.99")
+
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected setting an unknown address to fail, got %v", err)
+	}
+
+	err = session.SetFloatingInterface("eth0", "10.209.0.30")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	floating, err := session.GetFloatingInterface("eth0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Floating: %v", floating)
+
+	if floating == nil || floating.FloatingIP != "10.209.0.30" {
+		t.Fatalf("Unexpected floating interface: %+v", floating)
+	}
+
+	floatings, err := session.GetAllFloatingInterfaces()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(floatings) != 1 || floatings[0].Interface != "eth0" {
+		t.Fatalf("Unexpected floating interfaces: %+v", floatings)
+	}
+
+	// done, delete the floating interface
+	deleted, err := session.DeleteFloatingInterface("eth0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !deleted {
+		t.Fatal("Expected deleting the floating interface to succeed, but failed")
+	}
+
+	floating, err = session.GetFloatingInterface("eth0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if floating != nil {
+		t.Fatalf("Expected the floating interface to be deleted: %v", floating)
+	}
+}
+
+func TestCreateVLANInterfaceIPv6(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	// IPv4 addresses are rejected
+	_, err := session.CreateVLANInterfaceIPv6("eth0", 300, netip.MustParsePrefix("10.209.3.10/24"), netip.Addr{})
+
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected an IPv4 address to fail, got %v", err)
+	}
+
+	vlan, err := session.CreateVLANInterfaceIPv6("eth0", 300, netip.MustParsePrefix("2001:db8:300::10/64"), netip.MustParseAddr("2001:db8:300::1"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix, err := vlan.Prefix()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prefix != netip.MustParsePrefix("2001:db8:300::10/64") || vlan.Gateway != "2001:db8:300::1" {
+		t.Fatalf("Unexpected VLAN interface: %+v", vlan)
+	}
+
+	// configure a NIC, too
+	err = session.UpdateNetworkInterfaceIPv6("eth1", netip.MustParsePrefix("2001:db8:1::10/48"), netip.Addr{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nic, err := session.GetNetworkInterface("eth1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix, err = nic.Prefix()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prefix.Bits() != 48 || !prefix.Addr().Is6() {
+		t.Fatalf("Unexpected NIC: %+v", nic)
 	}
 }
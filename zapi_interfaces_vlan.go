@@ -0,0 +1,238 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+//
+// VLAN Interfaces
+//
+
+type vlanInterfaceListResponse struct {
+	Description string                 `json:"description"`
+	Interfaces  []VLANInterfaceDetails `json:"interfaces"`
+}
+
+type vlanInterfaceDetailsResponse struct {
+	Description string               `json:"description"`
+	Interface   VLANInterfaceDetails `json:"interface"`
+}
+
+// VLANInterfaceDetails contains all information regarding a VLAN interface.
+// The name consists of the parent NIC and the VLAN tag, e.g. "eth0.100".
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#vlan-interface
+type VLANInterfaceDetails struct {
+	Name    string          `json:"name"`
+	Parent  string          `json:"parent"`
+	IP      string          `json:"ip"`
+	Netmask string          `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	MAC     string          `json:"mac"`
+	Status  InterfaceStatus `json:"status"`
+}
+
+// String returns the VLAN interface's name and IP address.
+func (vd *VLANInterfaceDetails) String() string {
+	return fmt.Sprintf("%v (%v)", vd.Name, vd.IP)
+}
+
+// Tag returns the VLAN tag, which is the suffix of the name, or 0 if the name is invalid.
+func (vd *VLANInterfaceDetails) Tag() int {
+	idx := strings.LastIndex(vd.Name, ".")
+
+	if idx < 0 {
+		return 0
+	}
+
+	tag, err := strconv.Atoi(vd.Name[idx+1:])
+
+	if err != nil {
+		return 0
+	}
+
+	return tag
+}
+
+// Addr returns the IP address of the VLAN interface.
+func (vd *VLANInterfaceDetails) Addr() (netip.Addr, error) {
+	return parseIPAddress(vd.IP)
+}
+
+// Prefix returns the IP address and the prefix length of the VLAN interface, e.g. "10.0.0.10/24" or "2001:db8::10/64".
+func (vd *VLANInterfaceDetails) Prefix() (netip.Prefix, error) {
+	return parseIPPrefix(vd.IP, vd.Netmask)
+}
+
+// VLANInterfaceName returns the name of a VLAN interface, e.g. "eth0.100".
+func VLANInterfaceName(parentName string, tag int) string {
+	return fmt.Sprintf("%v.%v", parentName, tag)
+}
+
+// GetAllVLANInterfaces returns list of all VLAN interfaces.
+func (s *ZapiSession) GetAllVLANInterfaces() ([]VLANInterfaceDetails, error) {
+	return s.GetAllVLANInterfacesContext(context.Background())
+}
+
+// GetAllVLANInterfacesContext returns list of all VLAN interfaces using the provided context.
+func (s *ZapiSession) GetAllVLANInterfacesContext(ctx context.Context) ([]VLANInterfaceDetails, error) {
+	var result *vlanInterfaceListResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "vlan")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Interfaces, nil
+}
+
+// GetVLANInterface returns details on a specific VLAN interface, or *nil* if not found.
+func (s *ZapiSession) GetVLANInterface(vlanInterfaceName string) (*VLANInterfaceDetails, error) {
+	return s.GetVLANInterfaceContext(context.Background(), vlanInterfaceName)
+}
+
+// GetVLANInterfaceContext returns details on a specific VLAN interface using the provided context.
+func (s *ZapiSession) GetVLANInterfaceContext(ctx context.Context, vlanInterfaceName string) (*VLANInterfaceDetails, error) {
+	var result *vlanInterfaceDetailsResponse
+
+	err := s.getForEntity(ctx, &result, "interfaces", "vlan", vlanInterfaceName)
+
+	if err != nil {
+		// interface not found?
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result.Interface, nil
+}
+
+type vlanInterfaceCreate struct {
+	Name    string `json:"name"`
+	IP      string `json:"ip"`
+	Netmask string `json:"netmask"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// CreateVLANInterface creates a new VLAN interface on a NIC. The *gateway* is optional.
+func (s *ZapiSession) CreateVLANInterface(parentName string, tag int, ip string, netmask string, gateway string) (*VLANInterfaceDetails, error) {
+	return s.CreateVLANInterfaceContext(context.Background(), parentName, tag, ip, netmask, gateway)
+}
+
+// CreateVLANInterfaceContext creates a new VLAN interface on a NIC using the provided context.
+func (s *ZapiSession) CreateVLANInterfaceContext(ctx context.Context, parentName string, tag int, ip string, netmask string, gateway string) (*VLANInterfaceDetails, error) {
+	if tag < 1 || tag > 4094 {
+		return nil, fmt.Errorf("Invalid VLAN tag %v, expected 1 to 4094", tag)
+	}
+
+	if _, err := parseIPPrefix(ip, netmask); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrValidation)
+	}
+
+	name := VLANInterfaceName(parentName, tag)
+
+	req := vlanInterfaceCreate{
+		Name:    name,
+		IP:      ip,
+		Netmask: netmask,
+		Gateway: gateway,
+	}
+
+	err := s.post(ctx, req, "interfaces", "vlan")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve status
+	return s.GetVLANInterfaceContext(ctx, name)
+}
+
+// CreateVLANInterfaceIPv6 creates a new VLAN interface on a NIC with an IPv6 address and prefix length, e.g. "2001:db8::10/64".
+// The *gateway* is optional and can be the zero value.
+func (s *ZapiSession) CreateVLANInterfaceIPv6(parentName string, tag int, prefix netip.Prefix, gateway netip.Addr) (*VLANInterfaceDetails, error) {
+	return s.CreateVLANInterfaceIPv6Context(context.Background(), parentName, tag, prefix, gateway)
+}
+
+// CreateVLANInterfaceIPv6Context creates a new VLAN interface on a NIC with an IPv6 address using the provided context.
+func (s *ZapiSession) CreateVLANInterfaceIPv6Context(ctx context.Context, parentName string, tag int, prefix netip.Prefix, gateway netip.Addr) (*VLANInterfaceDetails, error) {
+	ip, netmask, gw, err := ipv6Config(prefix, gateway)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CreateVLANInterfaceContext(ctx, parentName, tag, ip, netmask, gw)
+}
+
+type vlanInterfaceUpdate struct {
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// UpdateVLANInterface updates the IP address, netmask, and gateway of a VLAN interface.
+func (s *ZapiSession) UpdateVLANInterface(vlan *VLANInterfaceDetails) error {
+	return s.UpdateVLANInterfaceContext(context.Background(), vlan)
+}
+
+// UpdateVLANInterfaceContext updates the IP address, netmask, and gateway of a VLAN interface using the provided context.
+func (s *ZapiSession) UpdateVLANInterfaceContext(ctx context.Context, vlan *VLANInterfaceDetails) error {
+	req := vlanInterfaceUpdate{
+		IP:      vlan.IP,
+		Netmask: vlan.Netmask,
+		Gateway: vlan.Gateway,
+	}
+
+	return s.put(ctx, req, "interfaces", "vlan", vlan.Name)
+}
+
+// DeleteVLANInterface will delete an existing VLAN interface (or do nothing if missing)
+func (s *ZapiSession) DeleteVLANInterface(vlanInterfaceName string) (bool, error) {
+	return s.DeleteVLANInterfaceContext(context.Background(), vlanInterfaceName)
+}
+
+// DeleteVLANInterfaceContext will delete an existing VLAN interface (or do nothing if missing) using the provided context.
+func (s *ZapiSession) DeleteVLANInterfaceContext(ctx context.Context, vlanInterfaceName string) (bool, error) {
+	// retrieve interface details
+	vlan, err := s.GetVLANInterfaceContext(ctx, vlanInterfaceName)
+
+	if err != nil {
+		return false, err
+	}
+
+	// interface does not exist?
+	if vlan == nil {
+		return false, nil
+	}
+
+	// delete the interface
+	return true, s.delete(ctx, "interfaces", "vlan", vlanInterfaceName)
+}
+
+// StartVLANInterface brings a VLAN interface up.
+func (s *ZapiSession) StartVLANInterface(vlanInterfaceName string) error {
+	return s.StartVLANInterfaceContext(context.Background(), vlanInterfaceName)
+}
+
+// StartVLANInterfaceContext brings a VLAN interface up using the provided context.
+func (s *ZapiSession) StartVLANInterfaceContext(ctx context.Context, vlanInterfaceName string) error {
+	return s.SetInterfaceStateContext(ctx, InterfaceType_VLAN, vlanInterfaceName, InterfaceStatus_Up)
+}
+
+// StopVLANInterface brings a VLAN interface down.
+func (s *ZapiSession) StopVLANInterface(vlanInterfaceName string) error {
+	return s.StopVLANInterfaceContext(context.Background(), vlanInterfaceName)
+}
+
+// StopVLANInterfaceContext brings a VLAN interface down using the provided context.
+func (s *ZapiSession) StopVLANInterfaceContext(ctx context.Context, vlanInterfaceName string) error {
+	return s.SetInterfaceStateContext(ctx, InterfaceType_VLAN, vlanInterfaceName, InterfaceStatus_Down)
+}
@@ -0,0 +1,232 @@
+package zevenetlb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//
+// Reconciliation Plans
+//
+
+// ErrPlanOutdated is returned when applying a plan after the farm has been changed since planning.
+var ErrPlanOutdated = errors.New("plan outdated")
+
+// FieldChange is a modified setting of a farm, service, or backend, named by its ZAPI field.
+// *Before* is *nil* for new entities, *After* is *nil* for deleted ones.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// String returns the field and its values, e.g. `contimeout: 20 -> 30`.
+func (fc FieldChange) String() string {
+	return fmt.Sprintf("%v: %v -> %v", fc.Field, formatPlanValue(fc.Before), formatPlanValue(fc.After))
+}
+
+func formatPlanValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(data)
+}
+
+// PlanOperation is a single pending ZAPI call.
+type PlanOperation struct {
+	// Method is the HTTP method, e.g. "PUT".
+	Method string `json:"method"`
+
+	// Path is relative to the ZAPI, e.g. "farms/myfarm/services/default".
+	Path string `json:"path"`
+
+	// Body is sent as JSON.
+	Body interface{} `json:"body,omitempty"`
+
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// String returns the method and path of the operation, e.g. "PUT farms/myfarm".
+func (op *PlanOperation) String() string {
+	return fmt.Sprintf("%v %v", op.Method, op.Path)
+}
+
+func (op *PlanOperation) execute(ctx context.Context, s *ZapiSession) error {
+	path := strings.Split(op.Path, "/")
+
+	switch op.Method {
+	case "POST":
+		return s.post(ctx, op.Body, path...)
+	case "PUT":
+		return s.put(ctx, op.Body, path...)
+	case "DELETE":
+		return s.delete(ctx, path...)
+	}
+
+	return fmt.Errorf("Unknown method: %v", op.Method)
+}
+
+// Plan contains the pending ZAPI calls to reconcile a farm.
+// It can be reviewed as text or JSON, and applied later using *ApplyPlan()*.
+type Plan struct {
+	FarmName string `json:"farm"`
+
+	// Create is set if the farm does not exist yet.
+	Create bool `json:"create"`
+
+	// State is a checksum of the farm at the time of planning, or empty if the farm does not exist.
+	State string `json:"state,omitempty"`
+
+	Operations []*PlanOperation `json:"operations"`
+}
+
+// IsEmpty checks if the farm already matches the spec.
+func (p *Plan) IsEmpty() bool {
+	return len(p.Operations) <= 0
+}
+
+// String renders the plan as human-readable text, listing the changed fields of each operation.
+func (p *Plan) String() string {
+	var buffer bytes.Buffer
+
+	switch {
+	case p.IsEmpty():
+		fmt.Fprintf(&buffer, "Farm %v: no changes\n", p.FarmName)
+	case p.Create:
+		fmt.Fprintf(&buffer, "Farm %v (new): %v operations\n", p.FarmName, len(p.Operations))
+	default:
+		fmt.Fprintf(&buffer, "Farm %v: %v operations\n", p.FarmName, len(p.Operations))
+	}
+
+	for _, op := range p.Operations {
+		fmt.Fprintf(&buffer, "  %v\n", op)
+
+		for _, c := range op.Changes {
+			fmt.Fprintf(&buffer, "      %v\n", c)
+		}
+	}
+
+	return buffer.String()
+}
+
+// PlanReconcile computes the changes required to match the spec, without applying them.
+// See *Reconcile()* for details on the spec.
+func (s *ZapiSession) PlanReconcile(spec *FarmSpec) (*Plan, error) {
+	return s.PlanReconcileContext(context.Background(), spec)
+}
+
+// PlanReconcileContext computes the changes required to match the spec using the provided context.
+func (s *ZapiSession) PlanReconcileContext(ctx context.Context, spec *FarmSpec) (*Plan, error) {
+	return s.planFarm(ctx, spec)
+}
+
+// ApplyPlan applies a previously computed plan, if the farm has not been changed since planning.
+// Otherwise, an error matching *ErrPlanOutdated* is returned and nothing is applied.
+func (s *ZapiSession) ApplyPlan(plan *Plan) (*ReconcileResult, error) {
+	return s.ApplyPlanContext(context.Background(), plan)
+}
+
+// ApplyPlanContext applies a previously computed plan using the provided context.
+func (s *ZapiSession) ApplyPlanContext(ctx context.Context, plan *Plan) (*ReconcileResult, error) {
+	if plan.FarmName == "" {
+		return nil, errors.New("The farm name is required")
+	}
+
+	// check the current state
+	profile, err := s.getFarmProfile(ctx, plan.FarmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var state string
+
+	if profile != "" {
+		live, err := s.GetFarmContext(ctx, plan.FarmName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state, err = farmState(live)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if (profile == "") != plan.Create || state != plan.State {
+		return nil, fmt.Errorf("Farm %v has been changed since planning: %w", plan.FarmName, ErrPlanOutdated)
+	}
+
+	return s.applyPlan(ctx, plan)
+}
+
+func (s *ZapiSession) applyPlan(ctx context.Context, plan *Plan) (*ReconcileResult, error) {
+	result := &ReconcileResult{
+		FarmName: plan.FarmName,
+		Created:  plan.Create,
+	}
+
+	for _, op := range plan.Operations {
+		err := op.execute(ctx, s)
+
+		if err != nil {
+			return result, fmt.Errorf("Failed to reconcile farm %v, %v: %w", plan.FarmName, op, err)
+		}
+
+		result.Operations = append(result.Operations, op.String())
+	}
+
+	if !result.IsChanged() {
+		return result, nil
+	}
+
+	// restart, if required
+	var err error
+
+	result.Restarted, err = s.restartIfNeeded(ctx, plan.FarmName)
+
+	return result, err
+}
+
+// farmState returns a checksum of the farm's settings, services, backends and certificates.
+// The status of the farm and its backends is ignored, as it changes without modifications.
+func farmState(farm *FarmDetails) (string, error) {
+	state := *farm
+	state.Status = ""
+	state.Services = nil
+
+	for _, svc := range farm.Services {
+		svc.Backends = append([]BackendDetails(nil), svc.Backends...)
+
+		for b := range svc.Backends {
+			svc.Backends[b].Status = ""
+		}
+
+		state.Services = append(state.Services, svc)
+	}
+
+	data, err := jsonMarshal(state)
+
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
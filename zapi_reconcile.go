@@ -0,0 +1,679 @@
+package zevenetlb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//
+// Farm Reconciliation
+//
+
+// defaultCertificateFilename is the certificate shipped with every loadbalancer.
+const defaultCertificateFilename = "zencert.pem"
+
+// FarmSpec describes the desired state of an HTTP or HTTPS farm, including its services and backends.
+// Services are identified by their name, backends by their IP address and port.
+// Empty strings, zero numbers, *false* and *nil* values keep the current setting, or the default of a new farm.
+type FarmSpec struct {
+	// Farm contains the desired farm settings, *FarmName* and *VirtualIP* are required.
+	// The *Services* of the farm contain the desired services and their *Backends*.
+	// If *Certificates* is not empty, the certificates are bound in the given order.
+	Farm FarmDetails
+
+	// Flags contains boolean settings to be set explicitly. As *false* cannot be told apart from an unset
+	// field of *Farm*, use the flags to turn a setting off. Flags override the fields of *Farm*.
+	Flags FarmFlags
+
+	// KeepUnmanaged disables deleting services and backends which are not part of the spec.
+	KeepUnmanaged bool
+}
+
+// FarmFlags contains the boolean settings of a farm and its services for *FarmSpec*.
+// Unset flags (*OptionalBool_Nil*) keep the current setting.
+type FarmFlags struct {
+	DisableSSLv2  OptionalBool `json:"disable_sslv2,omitempty"`
+	DisableSSLv3  OptionalBool `json:"disable_sslv3,omitempty"`
+	DisableTLSv1  OptionalBool `json:"disable_tlsv1,omitempty"`
+	DisableTLSv11 OptionalBool `json:"disable_tlsv1_1,omitempty"`
+	DisableTLSv12 OptionalBool `json:"disable_tlsv1_2,omitempty"`
+
+	// Services contains the flags of the services, by service name.
+	Services map[string]ServiceFlags `json:"-"`
+}
+
+// ServiceFlags contains the boolean settings of a service for *FarmSpec*.
+// Unset flags (*OptionalBool_Nil*) keep the current setting.
+type ServiceFlags struct {
+	FarmGuardianEnabled          OptionalBool `json:"fgenabled,omitempty"`
+	EncryptedBackends            OptionalBool `json:"httpsb,omitempty"`
+	LastResponseBalancingEnabled OptionalBool `json:"leastresp,omitempty"`
+}
+
+// Flags returns all boolean settings of the farm and its services as explicit flags,
+// e.g. to create a spec which reproduces the farm exactly.
+func (fd *FarmDetails) Flags() FarmFlags {
+	flags := FarmFlags{
+		DisableSSLv2:  optionalBool(fd.DisableSSLv2),
+		DisableSSLv3:  optionalBool(fd.DisableSSLv3),
+		DisableTLSv1:  optionalBool(fd.DisableTLSv1),
+		DisableTLSv11: optionalBool(fd.DisableTLSv11),
+		DisableTLSv12: optionalBool(fd.DisableTLSv12),
+		Services:      map[string]ServiceFlags{},
+	}
+
+	for _, svc := range fd.Services {
+		flags.Services[svc.ServiceName] = ServiceFlags{
+			FarmGuardianEnabled:          optionalBool(svc.FarmGuardianEnabled),
+			EncryptedBackends:            optionalBool(svc.EncryptedBackends),
+			LastResponseBalancingEnabled: optionalBool(svc.LastResponseBalancingEnabled),
+		}
+	}
+
+	return flags
+}
+
+func optionalBool(value bool) OptionalBool {
+	if value {
+		return OptionalBool_True
+	}
+
+	return OptionalBool_False
+}
+
+// ReconcileResult contains the changes applied by *Reconcile()*.
+type ReconcileResult struct {
+	FarmName string
+
+	// Created is set if the farm did not exist before.
+	Created bool
+
+	// Operations contains the applied ZAPI calls, e.g. "PUT farms/myfarm/services/default".
+	Operations []string
+
+	// Restarted is set if the farm has been restarted to apply the changes.
+	Restarted bool
+}
+
+// IsChanged checks if any changes have been applied.
+func (rr *ReconcileResult) IsChanged() bool {
+	return len(rr.Operations) > 0
+}
+
+// String returns the farm's name and the number of applied changes.
+func (rr *ReconcileResult) String() string {
+	return fmt.Sprintf("%v (Operations: %v, Restarted: %v)", rr.FarmName, len(rr.Operations), rr.Restarted)
+}
+
+// Reconcile creates or updates a farm, its services and its backends to match the spec.
+// The farm is restarted only if the changes require it.
+func (s *ZapiSession) Reconcile(spec *FarmSpec) (*ReconcileResult, error) {
+	return s.ReconcileContext(context.Background(), spec)
+}
+
+// ReconcileContext creates or updates a farm, its services and its backends to match the spec using the provided context.
+func (s *ZapiSession) ReconcileContext(ctx context.Context, spec *FarmSpec) (*ReconcileResult, error) {
+	plan, err := s.PlanReconcileContext(ctx, spec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyPlan(ctx, plan)
+}
+
+func (s *ZapiSession) restartIfNeeded(ctx context.Context, farmName string) (bool, error) {
+	farm, err := s.GetFarmContext(ctx, farmName)
+
+	if err != nil {
+		return false, err
+	}
+
+	if farm == nil || farm.Status != FarmStatus_NeedsRestart {
+		return false, nil
+	}
+
+	return true, s.RestartFarmContext(ctx, farmName)
+}
+
+var (
+	farmUnmanagedFields    = []string{"farmname", "status", "certlist", "services"}
+	serviceUnmanagedFields = []string{"id", "farmname", "backends"}
+	farmGuardianFields     = []string{"fgenabled", "fglog", "fgscript", "fgtimecheck"}
+	backendUnmanagedFields = []string{"id", "ip", "port", "status", "farmname", "servicename"}
+)
+
+// planFarm computes the operations required to reach the desired state, in the order they have to be applied.
+func (s *ZapiSession) planFarm(ctx context.Context, spec *FarmSpec) (*Plan, error) {
+	desired := spec.Farm
+	farmName := desired.FarmName
+
+	if farmName == "" {
+		return nil, errors.New("The farm name is required")
+	}
+
+	// only HTTP farms are supported
+	profile, err := s.getFarmProfile(ctx, farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != "" && profile != FarmProfile_HTTP && profile != FarmProfile_HTTPS {
+		return nil, fmt.Errorf("Farm %v is not an HTTP farm: %v", farmName, profile)
+	}
+
+	plan := &Plan{
+		FarmName: farmName,
+		Create:   profile == "",
+	}
+
+	var live *FarmDetails
+
+	if plan.Create {
+		if desired.VirtualIP == "" {
+			return nil, fmt.Errorf("The virtual IP of farm %v is required", farmName)
+		}
+
+		// create the farm
+		req := farmCreate{
+			FarmName:    farmName,
+			Profile:     string(FarmProfile_HTTP),
+			VirtualIP:   desired.VirtualIP,
+			VirtualPort: desired.VirtualPort,
+		}
+
+		if desired.Listener == "" {
+			desired.Listener = FarmListener_HTTP
+		}
+
+		if req.VirtualPort <= 0 {
+			if desired.Listener == FarmListener_HTTPS {
+				req.VirtualPort = 443
+			} else {
+				req.VirtualPort = 80
+			}
+		}
+
+		op, err := planCreate(req, "farms")
+
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Operations = append(plan.Operations, op)
+
+		live = &FarmDetails{
+			FarmName:    farmName,
+			Listener:    FarmListener_HTTP,
+			VirtualIP:   req.VirtualIP,
+			VirtualPort: req.VirtualPort,
+		}
+	} else {
+		live, err = s.GetFarmContext(ctx, farmName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if live == nil {
+			return nil, fmt.Errorf("Farm not found: %v", farmName)
+		}
+
+		plan.State, err = farmState(live)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the same address in another notation is not a change
+	if sameIPAddress(desired.VirtualIP, live.VirtualIP) {
+		desired.VirtualIP = live.VirtualIP
+	}
+
+	// update the farm settings
+	op, err := planUpdate(live, &desired, &spec.Flags, farmUnmanagedFields, false, "farms", farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if op != nil {
+		plan.Operations = append(plan.Operations, op)
+	}
+
+	// bind the certificates
+	listener := desired.Listener
+
+	if listener == "" {
+		listener = live.Listener
+	}
+
+	if listener == FarmListener_HTTPS && len(desired.Certificates) > 0 {
+		var liveCerts, desiredCerts []string
+
+		for _, c := range live.Certificates {
+			liveCerts = append(liveCerts, c.Filename)
+		}
+
+		// the loadbalancer binds the default certificate when the listener becomes https
+		if live.Listener != FarmListener_HTTPS && len(liveCerts) <= 0 {
+			liveCerts = []string{defaultCertificateFilename}
+		}
+
+		for _, c := range desired.Certificates {
+			desiredCerts = append(desiredCerts, c.Filename)
+		}
+
+		plan.Operations = append(plan.Operations, planCertificates(farmName, liveCerts, desiredCerts)...)
+	}
+
+	// delete the services not in the spec
+	if !spec.KeepUnmanaged {
+		for _, ls := range live.Services {
+			if ds, _ := desired.GetService(ls.ServiceName); ds == nil {
+				plan.Operations = append(plan.Operations, &PlanOperation{
+					Method:  "DELETE",
+					Path:    joinPath("farms", farmName, "services", ls.ServiceName),
+					Changes: []FieldChange{{Field: "id", Before: ls.ServiceName}},
+				})
+			}
+		}
+	}
+
+	// create and update the services
+	for i := range desired.Services {
+		flags := spec.Flags.Services[desired.Services[i].ServiceName]
+
+		ops, err := planService(live, &desired.Services[i], &flags, spec.KeepUnmanaged)
+
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Operations = append(plan.Operations, ops...)
+	}
+
+	return plan, nil
+}
+
+func planService(farm *FarmDetails, desired *ServiceDetails, flags *ServiceFlags, keepUnmanaged bool) ([]*PlanOperation, error) {
+	var ops []*PlanOperation
+
+	farmName := farm.FarmName
+	serviceName := desired.ServiceName
+
+	if serviceName == "" {
+		return nil, fmt.Errorf("The service name on farm %v is required", farmName)
+	}
+
+	live, _ := farm.GetService(serviceName)
+
+	if live == nil {
+		op, err := planCreate(serviceCreate{ServiceName: serviceName}, "farms", farmName, "services")
+
+		if err != nil {
+			return nil, err
+		}
+
+		ops = append(ops, op)
+
+		live = &ServiceDetails{
+			ServiceName: serviceName,
+			FarmName:    farmName,
+		}
+	}
+
+	// update the service settings
+	op, err := planUpdate(live, desired, flags, append(serviceUnmanagedFields, farmGuardianFields...), false, "farms", farmName, "services", serviceName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if op != nil {
+		ops = append(ops, op)
+	}
+
+	// update farm guardian
+	op, err = planUpdate(live, desired, flags, farmGuardianFields, true, "farms", farmName, "fg")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if op != nil {
+		body := op.Body.(map[string]interface{})
+		body["service"] = serviceName
+
+		if body["fgenabled"] == "true" && desired.FarmGuardianScript == "" && live.FarmGuardianScript == "" {
+			body["fgscript"] = "check_http -H HOST -p PORT"
+		}
+
+		ops = append(ops, op)
+	}
+
+	// update the existing backends first, as the IDs are positional and change on deletion,
+	// then delete the backends not in the spec in descending order, and create the missing ones at last
+	var createOps []*PlanOperation
+
+	for i := range desired.Backends {
+		db := &desired.Backends[i]
+
+		if db.IPAddress == "" || db.Port <= 0 {
+			return nil, fmt.Errorf("The address of a backend of service %v on farm %v is required", serviceName, farmName)
+		}
+
+		lb, _ := live.GetBackendByAddress(db.IPAddress, db.Port)
+
+		if lb == nil {
+			req := backendUpdate{
+				IPAddress:      db.IPAddress,
+				Port:           db.Port,
+				TimeoutSeconds: db.TimeoutSeconds,
+				Weight:         db.Weight,
+			}
+
+			op, err := planCreate(req, "farms", farmName, "services", serviceName, "backends")
+
+			if err != nil {
+				return nil, err
+			}
+
+			createOps = append(createOps, op)
+
+			continue
+		}
+
+		// the same address in another notation is not a change
+		update := *db
+		update.IPAddress = lb.IPAddress
+
+		op, err := planUpdate(lb, &update, nil, backendUnmanagedFields, false, "farms", farmName, "services", serviceName, "backends", strconv.Itoa(lb.ID))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+
+	if !keepUnmanaged {
+		backends := append([]BackendDetails(nil), live.Backends...)
+
+		sort.Slice(backends, func(i, j int) bool {
+			return backends[i].ID > backends[j].ID
+		})
+
+		for _, lb := range backends {
+			if db, _ := desired.GetBackendByAddress(lb.IPAddress, lb.Port); db == nil {
+				ops = append(ops, &PlanOperation{
+					Method: "DELETE",
+					Path:   joinPath("farms", farmName, "services", serviceName, "backends", strconv.Itoa(lb.ID)),
+					Changes: []FieldChange{
+						{Field: "ip", Before: lb.IPAddress},
+						{Field: "port", Before: lb.Port},
+					},
+				})
+			}
+		}
+	}
+
+	return append(ops, createOps...), nil
+}
+
+type backendUpdate struct {
+	IPAddress      string `json:"ip"`
+	Port           int    `json:"port"`
+	TimeoutSeconds *int   `json:"timeout,omitempty"`
+	Weight         *int   `json:"weight,omitempty"`
+}
+
+// planCreate returns a POST operation, listing all fields of the new entity as changes.
+func planCreate(body interface{}, path ...string) (*PlanOperation, error) {
+	fields, err := jsonFields(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	op := &PlanOperation{
+		Method: "POST",
+		Path:   joinPath(path...),
+		Body:   body,
+	}
+
+	for k, v := range fields {
+		op.Changes = append(op.Changes, FieldChange{Field: k, After: v})
+	}
+
+	sortChanges(op.Changes)
+
+	return op, nil
+}
+
+// planCertificates returns the operations binding the certificates in the given order, or *nil* if nothing changed.
+// As certificates are always appended, the last one stays bound to keep the farm valid and is moved to the end at last.
+func planCertificates(farmName string, current []string, filenames []string) []*PlanOperation {
+	if len(filenames) <= 0 || strings.Join(current, "\n") == strings.Join(filenames, "\n") {
+		return nil
+	}
+
+	var ops []*PlanOperation
+
+	add := func(filename string) {
+		ops = append(ops, &PlanOperation{
+			Method:  "POST",
+			Path:    joinPath("farms", farmName, "certificates"),
+			Body:    farmCertificateAdd{Filename: filename},
+			Changes: []FieldChange{{Field: "file", After: filename}},
+		})
+	}
+
+	remove := func(filename string) {
+		ops = append(ops, &PlanOperation{
+			Method:  "DELETE",
+			Path:    joinPath("farms", farmName, "certificates", filename),
+			Changes: []FieldChange{{Field: "file", Before: filename}},
+		})
+	}
+
+	last := filenames[len(filenames)-1]
+	bound := false
+
+	for _, c := range current {
+		bound = bound || c == last
+	}
+
+	if !bound {
+		add(last)
+	}
+
+	for _, c := range current {
+		if c != last {
+			remove(c)
+		}
+	}
+
+	for _, f := range filenames[:len(filenames)-1] {
+		add(f)
+	}
+
+	// move the last certificate to the end
+	if len(filenames) > 1 {
+		remove(last)
+		add(last)
+	}
+
+	return ops
+}
+
+// planUpdate returns a PUT operation containing the changed fields only, or *nil* if nothing changed.
+// If *include* is set, only the listed *fields* are compared, otherwise the listed fields are skipped.
+func planUpdate(live interface{}, desired interface{}, flags interface{}, fields []string, include bool, path ...string) (*PlanOperation, error) {
+	changes, err := diffFields(live, desired, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := make(map[string]interface{})
+
+	var filtered []FieldChange
+
+	for _, c := range changes {
+		if contains(fields, c.Field) != include {
+			continue
+		}
+
+		filtered = append(filtered, c)
+		body[c.Field] = c.After
+	}
+
+	if len(filtered) <= 0 {
+		return nil, nil
+	}
+
+	return &PlanOperation{
+		Method:  "PUT",
+		Path:    joinPath(path...),
+		Body:    body,
+		Changes: filtered,
+	}, nil
+}
+
+// diffFields compares the JSON representations of two entities of the same type.
+// Empty strings, zero numbers, *false*, *null* values, lists and objects of the desired entity are skipped.
+// The *flags* are optional, their set fields override the desired entity, including *false*.
+func diffFields(live interface{}, desired interface{}, flags interface{}) ([]FieldChange, error) {
+	liveFields, err := jsonFields(live)
+
+	if err != nil {
+		return nil, err
+	}
+
+	desiredFields, err := jsonFields(desired)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// false cannot be told apart from unset
+	for _, k := range boolFields(desired) {
+		if v := desiredFields[k]; v == false || v == "false" {
+			delete(desiredFields, k)
+		}
+	}
+
+	if flags != nil {
+		flagFields, err := jsonFields(flags)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range flagFields {
+			desiredFields[k] = v
+		}
+	}
+
+	var changes []FieldChange
+
+	for k, v := range desiredFields {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			if v == "" {
+				continue
+			}
+		case float64:
+			if v == 0 {
+				continue
+			}
+		case bool:
+		default:
+			// lists and objects are managed separately
+			continue
+		}
+
+		if reflect.DeepEqual(liveFields[k], v) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Field:  k,
+			Before: liveFields[k],
+			After:  v,
+		})
+	}
+
+	sortChanges(changes)
+
+	return changes, nil
+}
+
+// boolFields returns the JSON names of the boolean fields of an entity.
+func boolFields(entity interface{}) []string {
+	t := reflect.TypeOf(entity)
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var names []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Type.Kind() == reflect.Bool {
+			names = append(names, strings.Split(f.Tag.Get("json"), ",")[0])
+		}
+	}
+
+	return names
+}
+
+func sortChanges(changes []FieldChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+}
+
+// jsonFields returns the fields of an entity as they are sent to the ZAPI.
+func jsonFields(entity interface{}) (map[string]interface{}, error) {
+	data, err := jsonMarshal(entity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+
+	err = json.Unmarshal(data, &fields)
+
+	return fields, err
+}
+
+func joinPath(path ...string) string {
+	return strings.Join(path, "/")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,469 @@
+package zevenetlb
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffFields(t *testing.T) {
+	weight := 5
+
+	live := &BackendDetails{ID: 1, IPAddress: "10.0.0.1", Port: 80, Status: BackendStatus_Up}
+	desired := &BackendDetails{IPAddress: "10.0.0.1", Port: 8080, Weight: &weight}
+
+	changes, err := diffFields(live, desired, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the empty status and ID are not managed
+	if len(changes) != 2 || changes[0].Field != "port" || changes[1].Field != "weight" || changes[1].Before != nil || changes[1].After != float64(5) {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+}
+
+func TestPlanCertificates(t *testing.T) {
+	ops := planCertificates("web", []string{"a.pem", "b.pem"}, []string{"b.pem", "c.pem"})
+
+	var actual []string
+
+	for _, op := range ops {
+		actual = append(actual, fmt.Sprintf("%v %v", op, op.Changes))
+	}
+
+	expected := []string{
+		"POST farms/web/certificates [file: (none) -> \"c.pem\"]",
+		"DELETE farms/web/certificates/a.pem [file: \"a.pem\" -> (none)]",
+		"DELETE farms/web/certificates/b.pem [file: \"b.pem\" -> (none)]",
+		"POST farms/web/certificates [file: (none) -> \"b.pem\"]",
+		"DELETE farms/web/certificates/c.pem [file: \"c.pem\" -> (none)]",
+		"POST farms/web/certificates [file: (none) -> \"c.pem\"]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected operations:\n%v", strings.Join(actual, "\n"))
+	}
+
+	if ops := planCertificates("web", []string{"a.pem"}, []string{"a.pem"}); ops != nil {
+		t.Fatalf("Expected no operations, but got %v", ops)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the farm does not exist
+	_, err := session.DeleteFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weight := 2
+
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:       unitTestFarmName,
+			VirtualIP:      unitTestVirtualIP,
+			ErrorString503: "Service unavailable",
+			Services: []ServiceDetails{
+				{
+					ServiceName: "service1",
+					HostPattern: "www.example.com",
+					Backends: []BackendDetails{
+						{IPAddress: "176.58.123.25", Port: 80},
+						{IPAddress: "176.58.123.26", Port: 80, Weight: &weight},
+					},
+				},
+			},
+		},
+	}
+
+	// create the farm
+	result, err := session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteFarm(unitTestFarmName)
+
+	t.Logf("Result: %v, Operations: %v", result, result.Operations)
+
+	if !result.Created || !result.IsChanged() {
+		t.Fatalf("Expected the farm to be created: %v", result)
+	}
+
+	farm, err := session.GetFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.ErrorString503 != spec.Farm.ErrorString503 || len(farm.Services) != 1 || len(farm.Services[0].Backends) != 2 {
+		t.Fatalf("Farm does not match the spec: %v", farm)
+	}
+
+	if farm.Status == FarmStatus_NeedsRestart {
+		t.Fatal("Expected the farm to be restarted")
+	}
+
+	// nothing to do
+	result, err = session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.IsChanged() || result.Restarted {
+		t.Fatalf("Expected no changes, but got %v", result.Operations)
+	}
+
+	// remove a backend and update the other one
+	weight = 3
+	spec.Farm.Services[0].Backends = spec.Farm.Services[0].Backends[1:]
+
+	result, err = session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Result: %v, Operations: %v", result, result.Operations)
+
+	if len(result.Operations) != 2 || !result.Restarted {
+		t.Fatalf("Unexpected operations: %v", result.Operations)
+	}
+
+	farm, err = session.GetFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backends := farm.Services[0].Backends
+
+	if len(backends) != 1 || backends[0].IPAddress != "176.58.123.26" || backends[0].Weight == nil || *backends[0].Weight != 3 {
+		t.Fatalf("Unexpected backends: %v", backends)
+	}
+}
+
+func TestPlanReconcile(t *testing.T) {
+	session := createTestSession(t)
+
+	// ensure the farm does not exist
+	_, err := session.DeleteFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:  unitTestFarmName,
+			VirtualIP: unitTestVirtualIP,
+			Services: []ServiceDetails{
+				{
+					ServiceName: "service1",
+					Backends: []BackendDetails{
+						{IPAddress: "176.58.123.25", Port: 80},
+					},
+				},
+			},
+		},
+	}
+
+	plan, err := session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Plan:\n%v", plan)
+
+	if !plan.Create || len(plan.Operations) != 3 || !strings.Contains(plan.String(), `vip: (none) -> "`+unitTestVirtualIP+`"`) {
+		t.Fatalf("Unexpected plan: %v", plan)
+	}
+
+	// the farm has not been created
+	farm, err := session.GetFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm != nil {
+		t.Fatal("Expected the farm to not exist")
+	}
+
+	// apply the plan after a roundtrip
+	data, err := json.Marshal(plan)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded Plan
+
+	err = json.Unmarshal(data, &loaded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = session.ApplyPlan(&loaded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.DeleteFarm(unitTestFarmName)
+
+	// plan an update
+	spec.Farm.ErrorString503 = "Service unavailable"
+
+	plan, err = session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Plan:\n%v", plan)
+
+	if len(plan.Operations) != 1 || plan.Operations[0].String() != "PUT farms/"+unitTestFarmName || plan.Operations[0].Changes[0].Field != "error503" {
+		t.Fatalf("Unexpected plan: %v", plan)
+	}
+
+	// change the farm after planning
+	_, err = session.CreateBackend(unitTestFarmName, "service1", "176.58.123.26", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = session.ApplyPlan(plan)
+
+	if !errors.Is(err, ErrPlanOutdated) {
+		t.Fatalf("Expected the plan to be outdated, but got %v", err)
+	}
+}
+
+func TestReconcileFlags(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	// disable SSLv3 and TLSv1, and enable farmguardian
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:     "samplefarm",
+			DisableSSLv3: true,
+			DisableTLSv1: true,
+			Services: []ServiceDetails{
+				{ServiceName: "default", FarmGuardianEnabled: true},
+			},
+		},
+		KeepUnmanaged: true,
+	}
+
+	_, err := session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a spec touching a single field leaves the flags alone
+	spec = &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:       "samplefarm",
+			ErrorString503: "Service unavailable",
+			Services: []ServiceDetails{
+				{ServiceName: "default"},
+			},
+		},
+		KeepUnmanaged: true,
+	}
+
+	plan, err := session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Plan:\n%v", plan)
+
+	if len(plan.Operations) != 1 || len(plan.Operations[0].Changes) != 1 || plan.Operations[0].Changes[0].Field != "error503" {
+		t.Fatalf("Unexpected plan: %v", plan)
+	}
+
+	// flags turn settings off explicitly
+	spec.Flags = FarmFlags{
+		DisableSSLv3: OptionalBool_False,
+		Services: map[string]ServiceFlags{
+			"default": {FarmGuardianEnabled: OptionalBool_False},
+		},
+	}
+
+	_, err = session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.DisableSSLv3 || !farm.DisableTLSv1 || farm.Services[0].FarmGuardianEnabled || farm.ErrorString503 != "Service unavailable" {
+		t.Fatalf("Unexpected farm: %+v", farm)
+	}
+}
+
+func TestReconcileDeleteBackends(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	for _, ip := range []string{"10.209.0.101", "10.209.0.102"} {
+		_, err := session.CreateBackend("samplefarm", "default", ip, 80)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// keep the second of three backends, changing its weight
+	weight := 3
+
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName: "samplefarm",
+			Services: []ServiceDetails{
+				{
+					ServiceName: "default",
+					Backends: []BackendDetails{
+						{IPAddress: "10.209.0.101", Port: 80, Weight: &weight},
+					},
+				},
+			},
+		},
+	}
+
+	plan, err := session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Plan:\n%v", plan)
+
+	// the IDs change on deletion, so the update comes first and the highest ID is deleted first
+	var actual []string
+
+	for _, op := range plan.Operations {
+		actual = append(actual, op.String())
+	}
+
+	expected := []string{
+		"PUT farms/samplefarm/services/default/backends/1",
+		"DELETE farms/samplefarm/services/default/backends/2",
+		"DELETE farms/samplefarm/services/default/backends/0",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected operations:\n%v", strings.Join(actual, "\n"))
+	}
+
+	_, err = session.ApplyPlan(plan)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := farm.Services[0]
+
+	if len(service.Backends) != 1 || service.Backends[0].IPAddress != "10.209.0.101" || service.Backends[0].Weight == nil || *service.Backends[0].Weight != 3 {
+		t.Fatalf("Unexpected backends: %+v", service.Backends)
+	}
+}
+
+func TestReconcileHTTPS(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	cert, key := createTestCertificate(t, "unittest.example.com", time.Now().Add(24*time.Hour))
+
+	_, err := session.UploadX509Certificate(unitTestCertificateName, []*x509.Certificate{cert}, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:     unitTestFarmName,
+			VirtualIP:    unitTestVirtualIP,
+			Listener:     FarmListener_HTTPS,
+			Certificates: []CertificateInfo{{Filename: unitTestCertificateName}},
+		},
+	}
+
+	_, err = session.Reconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the default certificate has been replaced
+	farm, err := session.GetFarm(unitTestFarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(farm.Certificates) != 1 || farm.Certificates[0].Filename != unitTestCertificateName {
+		t.Fatalf("Unexpected certificates: %v", farm.Certificates)
+	}
+
+	// nothing left to do
+	plan, err := session.PlanReconcile(spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.IsEmpty() {
+		t.Fatalf("Unexpected plan: %v", plan)
+	}
+}
+
+func TestReconcileError(t *testing.T) {
+	session := createFakeTestSession(t)
+
+	// the certificate does not exist
+	spec := &FarmSpec{
+		Farm: FarmDetails{
+			FarmName:     unitTestFarmName,
+			VirtualIP:    unitTestVirtualIP,
+			Listener:     FarmListener_HTTPS,
+			Certificates: []CertificateInfo{{Filename: "missing.pem"}},
+		},
+	}
+
+	_, err := session.Reconcile(spec)
+
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected a validation error, but got %v", err)
+	}
+
+	t.Logf("Error: %v", err)
+}
@@ -0,0 +1,144 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy defines how failed API calls are retried, e.g. while a farm is restarting.
+// Unset *MaxAttempts*, *InitialBackoff*, *MaxBackoff*, and *RetryableStatusCodes* use the values of *DefaultRetryPolicy()*.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It is doubled for every subsequent retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction (0.0 to 1.0) by which each delay is randomized, e.g. 0.2 for +/- 20%.
+	// Set to 0 to disable randomization.
+	Jitter float64
+
+	// RetryableStatusCodes contains the HTTP status codes causing a retry.
+	RetryableStatusCodes []int
+
+	// RetryNonIdempotent enables retrying non-idempotent requests (POST), which may create an entity twice.
+	RetryNonIdempotent bool
+
+	// OnRetry is called before waiting for the next attempt, if set.
+	OnRetry func(attempt RetryAttempt)
+}
+
+// RetryAttempt contains information about a failed attempt that is about to be retried.
+type RetryAttempt struct {
+	Attempt    int
+	Method     string
+	URL        string
+	StatusCode int
+	Err        error
+	Backoff    time.Duration
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most use cases: up to three attempts
+// for idempotent requests on connection errors and HTTP 502, 503, and 504.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       500 * time.Millisecond,
+		MaxBackoff:           10 * time.Second,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+func (p *RetryPolicy) withDefaults() *RetryPolicy {
+	def := DefaultRetryPolicy()
+	res := *p
+
+	if res.MaxAttempts <= 0 {
+		res.MaxAttempts = def.MaxAttempts
+	}
+	if res.InitialBackoff <= 0 {
+		res.InitialBackoff = def.InitialBackoff
+	}
+	if res.MaxBackoff <= 0 {
+		res.MaxBackoff = def.MaxBackoff
+	}
+	if res.RetryableStatusCodes == nil {
+		res.RetryableStatusCodes = def.RetryableStatusCodes
+	}
+
+	return &res
+}
+
+// shouldRetry checks if a failed attempt is to be retried.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	if !p.RetryNonIdempotent && strings.EqualFold(method, http.MethodPost) {
+		return false
+	}
+
+	// failed with an HTTP status code?
+	var reqErr RequestError
+
+	if errors.As(err, &reqErr) {
+		for _, c := range p.RetryableStatusCodes {
+			if c == reqErr.StatusCode {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// an untrusted certificate will not become trusted
+	var certErr CertificateError
+
+	if errors.As(err, &certErr) {
+		return false
+	}
+
+	// connection error
+	return true
+}
+
+// backoff returns the delay before the next attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
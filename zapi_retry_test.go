@@ -0,0 +1,140 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func createRetryTestSession(t *testing.T, failures int32, policy *RetryPolicy) (*ZapiSession, *int32, func()) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/system/version") {
+			w.Write([]byte(`{"description":"System version","params":{"appliance_version":"ZCE 5 (v5.0)"}}`))
+			return
+		}
+
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"description":"Farm","message":"Service unavailable"}`))
+			return
+		}
+
+		w.Write([]byte(`{"description":"List farms","params":[]}`))
+	}))
+
+	session, err := Connect(server.URL, "zapi-key", &ConfigOptions{RetryPolicy: policy})
+
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	return session, &calls, server.Close
+}
+
+func TestRetryTransientFailure(t *testing.T) {
+	var retries []RetryAttempt
+
+	policy := &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnRetry: func(attempt RetryAttempt) {
+			retries = append(retries, attempt)
+		},
+	}
+
+	session, calls, closeServer := createRetryTestSession(t, 2, policy)
+
+	defer closeServer()
+
+	_, err := session.GetAllFarms()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *calls != 3 {
+		t.Fatalf("Expected 3 calls, but got %v", *calls)
+	}
+
+	if len(retries) != 2 || retries[0].StatusCode != http.StatusServiceUnavailable || retries[1].Attempt != 2 {
+		t.Fatalf("Unexpected retries: %v", retries)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	session, calls, closeServer := createRetryTestSession(t, 5, &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+
+	defer closeServer()
+
+	_, err := session.GetAllFarms()
+
+	var reqErr RequestError
+
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+
+	if *calls != 2 {
+		t.Fatalf("Expected 2 calls, but got %v", *calls)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	session, calls, closeServer := createRetryTestSession(t, 1, &RetryPolicy{InitialBackoff: time.Millisecond})
+
+	defer closeServer()
+
+	// POST is not retried by default
+	_, err := session.CreateService("myfarm", "myservice")
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+
+	if *calls != 1 {
+		t.Fatalf("Expected 1 call, but got %v", *calls)
+	}
+}
+
+func TestRetryContextCancellation(t *testing.T) {
+	session, calls, closeServer := createRetryTestSession(t, 5, &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+	defer cancel()
+
+	_, err := session.GetAllFarmsContext(ctx)
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+
+	if *calls != 1 {
+		t.Fatalf("Expected 1 call, but got %v", *calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := (&RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}).withDefaults()
+	policy.Jitter = 0
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+
+	for i, e := range expected {
+		if b := policy.backoff(i + 1); b != e {
+			t.Fatalf("Expected backoff %v for attempt %v, but got %v", e, i+1, b)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// Rolling Deployments
+//
+
+// RollingDeployOptions configures *RollingDeploy()*.
+type RollingDeployOptions struct {
+	// MaxUnavailable is the number of backends out of service at the same time, including the backends
+	// already in maintenance or down. Defaults to 1.
+	MaxUnavailable int
+
+	// Drain configures the draining of each backend.
+	Drain DrainOptions
+
+	// Restore configures waiting for each backend to be up after the deployment.
+	Restore RestoreOptions
+
+	// Deploy is called for each drained backend, e.g. to update the application server.
+	// It is called concurrently if *MaxUnavailable* is greater than 1.
+	Deploy func(ctx context.Context, backend *BackendDetails) error
+}
+
+// RollingDeployResult summarizes a rolling deployment.
+type RollingDeployResult struct {
+	FarmName    string
+	ServiceName string
+
+	// Deployed contains the IDs of the backends deployed and restored successfully, in order.
+	Deployed []int
+
+	// Drains contains the results of draining the backends.
+	Drains []*DrainResult
+
+	Duration time.Duration
+}
+
+// String returns the service and the number of deployed backends.
+func (rr *RollingDeployResult) String() string {
+	return fmt.Sprintf("%v/%v: deployed %v backends in %v", rr.FarmName, rr.ServiceName, len(rr.Deployed), rr.Duration)
+}
+
+// RollingDeploy deploys the backends of a service in batches of *MaxUnavailable*: each backend is drained,
+// deployed using the callback, and restored, before the next batch is started.
+// Backends already in maintenance or down are skipped, but count against *MaxUnavailable*. If they
+// leave no room for a batch, the deployment is refused.
+//
+// If a backend fails, the deployment is aborted and the backends of the current batch are taken out of
+// maintenance again. The returned result contains the backends deployed so far.
+func (s *ZapiSession) RollingDeploy(farmName string, serviceName string, options *RollingDeployOptions) (*RollingDeployResult, error) {
+	return s.RollingDeployContext(context.Background(), farmName, serviceName, options)
+}
+
+// RollingDeployContext deploys the backends of a service in batches using the provided context.
+func (s *ZapiSession) RollingDeployContext(ctx context.Context, farmName string, serviceName string, options *RollingDeployOptions) (*RollingDeployResult, error) {
+	if options == nil || options.Deploy == nil {
+		return nil, errors.New("The deploy callback is required")
+	}
+
+	maxUnavailable := options.MaxUnavailable
+
+	if maxUnavailable <= 0 {
+		maxUnavailable = 1
+	}
+
+	// retrieve the backends
+	farm, err := s.GetFarmContext(ctx, farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if farm == nil {
+		return nil, fmt.Errorf("Farm %v not found: %w", farmName, ErrNotFound)
+	}
+
+	service, err := farm.GetService(serviceName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if service == nil {
+		return nil, fmt.Errorf("Service %v/%v not found: %w", farmName, serviceName, ErrNotFound)
+	}
+
+	var backends []*BackendDetails
+
+	unavailable := 0
+
+	for i := range service.Backends {
+		switch service.Backends[i].Status {
+		case BackendStatus_Maintenance, BackendStatus_Down:
+			unavailable++
+		default:
+			backends = append(backends, &service.Backends[i])
+		}
+	}
+
+	// the backends already out of service reduce the batch size
+	if len(backends) > 0 && unavailable >= maxUnavailable {
+		return nil, fmt.Errorf("Service %v/%v has %v backends in maintenance or down, no room left for a maximum of %v unavailable backends", farmName, serviceName, unavailable, maxUnavailable)
+	}
+
+	maxUnavailable -= unavailable
+
+	result := &RollingDeployResult{
+		FarmName:    farmName,
+		ServiceName: serviceName,
+	}
+
+	start := time.Now()
+
+	// deploy in batches
+	for len(backends) > 0 {
+		size := maxUnavailable
+
+		if size > len(backends) {
+			size = len(backends)
+		}
+
+		batch := backends[:size]
+		backends = backends[size:]
+
+		err = s.deployBatch(ctx, batch, options, result)
+
+		result.Duration = time.Since(start)
+
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// deployBatch deploys backends concurrently. On failure, the other backends are cancelled and restored.
+func (s *ZapiSession) deployBatch(ctx context.Context, batch []*BackendDetails, options *RollingDeployOptions, result *RollingDeployResult) error {
+	batchCtx, cancel := context.WithCancel(ctx)
+
+	defer cancel()
+
+	drains := make([]*DrainResult, len(batch))
+	errs := make([]error, len(batch))
+
+	var wg sync.WaitGroup
+
+	for i, backend := range batch {
+		wg.Add(1)
+
+		go func(i int, backend *BackendDetails) {
+			defer wg.Done()
+
+			drains[i], errs[i] = s.deployBackend(batchCtx, backend, options)
+
+			// abort the others
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, backend)
+	}
+
+	wg.Wait()
+
+	var failure error
+
+	for i, backend := range batch {
+		if drains[i] != nil {
+			result.Drains = append(result.Drains, drains[i])
+		}
+
+		if errs[i] == nil {
+			result.Deployed = append(result.Deployed, backend.ID)
+			continue
+		}
+
+		// report the cause, not the cancellation of the others
+		if failure == nil || (errors.Is(failure, context.Canceled) && !errors.Is(errs[i], context.Canceled)) {
+			failure = errs[i]
+		}
+	}
+
+	if failure == nil {
+		return nil
+	}
+
+	// restore the failed backends, even if the context has been cancelled
+	var restoreErrors []string
+
+	for i, backend := range batch {
+		if errs[i] == nil {
+			continue
+		}
+
+		err := s.SetBackendMaintenanceContext(context.Background(), backend, false, false)
+
+		if err != nil {
+			restoreErrors = append(restoreErrors, fmt.Sprintf("failed to restore backend %v/%v/%v: %v", backend.FarmName, backend.ServiceName, backend.ID, err))
+		}
+	}
+
+	if len(restoreErrors) > 0 {
+		return fmt.Errorf("%w; %v", failure, strings.Join(restoreErrors, "; "))
+	}
+
+	return failure
+}
+
+func (s *ZapiSession) deployBackend(ctx context.Context, backend *BackendDetails, options *RollingDeployOptions) (*DrainResult, error) {
+	drainOptions := options.Drain
+
+	drain, err := s.DrainBackendContext(ctx, backend, &drainOptions)
+
+	if err != nil {
+		return drain, fmt.Errorf("Failed to drain backend %v/%v/%v: %w", backend.FarmName, backend.ServiceName, backend.ID, err)
+	}
+
+	err = options.Deploy(ctx, backend)
+
+	if err != nil {
+		return drain, fmt.Errorf("Failed to deploy backend %v/%v/%v: %w", backend.FarmName, backend.ServiceName, backend.ID, err)
+	}
+
+	restoreOptions := options.Restore
+
+	err = s.RestoreBackendContext(ctx, backend, &restoreOptions)
+
+	if err != nil {
+		return drain, fmt.Errorf("Failed to restore backend %v/%v/%v: %w", backend.FarmName, backend.ServiceName, backend.ID, err)
+	}
+
+	return drain, nil
+}
@@ -0,0 +1,188 @@
+package zevenetlb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+func createRolloutTestSession(t *testing.T) (*ZapiSession, *zevenettest.Server) {
+	session, server, _ := createDrainTestSession(t, nil)
+
+	// the sample service has one backend, add two more
+	for _, ip := range []string{"10.209.0.101", "10.209.0.102"} {
+		_, err := session.CreateBackend("samplefarm", "default", ip, 80)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return session, server
+}
+
+func TestRollingDeploy(t *testing.T) {
+	session, _ := createRolloutTestSession(t)
+
+	var mutex sync.Mutex
+	var deployed []string
+
+	result, err := session.RollingDeploy("samplefarm", "default", &RollingDeployOptions{
+		MaxUnavailable: 2,
+		Drain:          DrainOptions{PollInterval: time.Millisecond},
+		Restore:        RestoreOptions{PollInterval: time.Millisecond},
+		Deploy: func(ctx context.Context, backend *BackendDetails) error {
+			stats, err := session.GetFarmStatsContext(ctx, backend.FarmName)
+
+			if err != nil {
+				return err
+			}
+
+			// at most two backends are unavailable
+			if service := stats.GetService("default"); service.BackendsUp != 1 && service.BackendsUp != 2 {
+				t.Errorf("Unexpected backends up: %v", service)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			deployed = append(deployed, backend.IPAddress)
+
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Result: %v", result)
+
+	if len(result.Deployed) != 3 || len(result.Drains) != 3 || len(deployed) != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// all backends are up again
+	stats, err := session.GetFarmStats("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if service := stats.GetService("default"); service.BackendsUp != 3 {
+		t.Fatalf("Expected all backends to be up: %v", service)
+	}
+}
+
+func TestRollingDeployFailure(t *testing.T) {
+	session, _ := createRolloutTestSession(t)
+
+	failure := errors.New("deployment failed")
+	calls := 0
+
+	result, err := session.RollingDeploy("samplefarm", "default", &RollingDeployOptions{
+		Drain:   DrainOptions{PollInterval: time.Millisecond},
+		Restore: RestoreOptions{PollInterval: time.Millisecond},
+		Deploy: func(ctx context.Context, backend *BackendDetails) error {
+			calls++
+
+			if backend.IPAddress == "10.209.0.101" {
+				return failure
+			}
+
+			return nil
+		},
+	})
+
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the deployment to fail, got %v", err)
+	}
+
+	t.Logf("Error: %v", err)
+
+	// the third backend has not been touched
+	if calls != 2 || len(result.Deployed) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// the failed backend has been restored
+	stats, err := session.GetFarmStats("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if service := stats.GetService("default"); service.BackendsUp != 3 {
+		t.Fatalf("Expected all backends to be up: %v", service)
+	}
+}
+
+func TestRollingDeployUnavailable(t *testing.T) {
+	session, server := createRolloutTestSession(t)
+
+	err := server.SetBackendStatus("samplefarm", "default", 1, "down")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var deployed []string
+
+	options := &RollingDeployOptions{
+		MaxUnavailable: 2,
+		Drain:          DrainOptions{PollInterval: time.Millisecond},
+		Restore:        RestoreOptions{PollInterval: time.Millisecond},
+		Deploy: func(ctx context.Context, backend *BackendDetails) error {
+			stats, err := session.GetFarmStatsContext(ctx, backend.FarmName)
+
+			if err != nil {
+				return err
+			}
+
+			// the backend which is down leaves room for one more
+			if service := stats.GetService("default"); service.BackendsUp != 1 {
+				t.Errorf("Unexpected backends up: %v", service)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			deployed = append(deployed, backend.IPAddress)
+
+			return nil
+		},
+	}
+
+	result, err := session.RollingDeploy("samplefarm", "default", options)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Result: %v", result)
+
+	// the backend which is down is skipped
+	if len(result.Deployed) != 2 || result.Deployed[0] != 0 || result.Deployed[1] != 2 || len(deployed) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// no room for a batch
+	options.MaxUnavailable = 1
+
+	_, err = session.RollingDeploy("samplefarm", "default", options)
+
+	if err == nil {
+		t.Fatal("Expected the deployment to be refused")
+	}
+
+	t.Logf("Error: %v", err)
+
+	if len(deployed) != 2 {
+		t.Fatalf("Unexpected deployments: %v", deployed)
+	}
+}
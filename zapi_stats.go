@@ -0,0 +1,369 @@
+package zevenetlb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//
+// Farm Statistics
+//
+
+type farmStatsResponse struct {
+	Description string             `json:"description"`
+	Backends    []backendStatsJSON `json:"backends"`
+}
+
+// backendStatsJSON is the raw backend statistics, the port is a string on L4xNAT farms.
+type backendStatsJSON struct {
+	ID          int             `json:"id"`
+	IPAddress   string          `json:"ip"`
+	Port        json.RawMessage `json:"port"`
+	Service     string          `json:"service"`
+	Established int             `json:"established"`
+	Pending     int             `json:"pending"`
+	Status      BackendStatus   `json:"status"`
+}
+
+// BackendStats contains the live connection statistics of a single backend.
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#show-farm-stats
+type BackendStats struct {
+	ID          int
+	IPAddress   string
+	Port        int
+	ServiceName string
+	Established int
+	Pending     int
+	Status      BackendStatus
+}
+
+// String returns the backend's IP, port, connections, and status.
+func (bs BackendStats) String() string {
+	return fmt.Sprintf("%v:%v (Established: %v, Pending: %v, Status: %v)", bs.IPAddress, bs.Port, bs.Established, bs.Pending, bs.Status)
+}
+
+// ServiceStats contains the connection statistics of all backends of a service.
+type ServiceStats struct {
+	ServiceName  string
+	Established  int
+	Pending      int
+	Backends     int
+	BackendsUp   int
+	BackendsDown int
+}
+
+// String returns the service's name and connections.
+func (ss ServiceStats) String() string {
+	return fmt.Sprintf("%v (Established: %v, Pending: %v, Backends up: %v/%v)", ss.ServiceName, ss.Established, ss.Pending, ss.BackendsUp, ss.Backends)
+}
+
+// FarmStats contains the live connection statistics of a farm.
+type FarmStats struct {
+	FarmName string
+	Backends []BackendStats
+	Services []ServiceStats
+}
+
+// String returns the farm's name and connections.
+func (fs *FarmStats) String() string {
+	return fmt.Sprintf("%v (Established: %v, Pending: %v)", fs.FarmName, fs.Established(), fs.Pending())
+}
+
+// Established returns the number of established connections of all backends.
+func (fs *FarmStats) Established() int {
+	res := 0
+
+	for _, b := range fs.Backends {
+		res += b.Established
+	}
+
+	return res
+}
+
+// Pending returns the number of pending connections of all backends.
+func (fs *FarmStats) Pending() int {
+	res := 0
+
+	for _, b := range fs.Backends {
+		res += b.Pending
+	}
+
+	return res
+}
+
+// GetBackend retrieves the statistics of a backend by its service and ID, or returns *nil* if not found.
+// The *serviceName* is empty for L4xNAT and DATALINK farms.
+func (fs *FarmStats) GetBackend(serviceName string, backendID int) *BackendStats {
+	for _, b := range fs.Backends {
+		if b.ServiceName == serviceName && b.ID == backendID {
+			return &b
+		}
+	}
+
+	return nil
+}
+
+// GetService retrieves the statistics of a service by its name, or returns *nil* if not found.
+func (fs *FarmStats) GetService(serviceName string) *ServiceStats {
+	for _, s := range fs.Services {
+		if s.ServiceName == serviceName {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+// GetFarmStats returns the live connection statistics of a farm.
+func (s *ZapiSession) GetFarmStats(farmName string) (*FarmStats, error) {
+	return s.GetFarmStatsContext(context.Background(), farmName)
+}
+
+// GetFarmStatsContext returns the live connection statistics of a farm using the provided context.
+func (s *ZapiSession) GetFarmStatsContext(ctx context.Context, farmName string) (*FarmStats, error) {
+	var result *farmStatsResponse
+
+	err := s.getForEntity(ctx, &result, "stats", "farms", farmName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildFarmStats(farmName, result.Backends)
+}
+
+func buildFarmStats(farmName string, backends []backendStatsJSON) (*FarmStats, error) {
+	stats := &FarmStats{
+		FarmName: farmName,
+	}
+
+	services := make(map[string]*ServiceStats)
+
+	for _, b := range backends {
+		port, err := parseFlexibleInt(b.Port)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port of backend %v: %v", b.ID, err)
+		}
+
+		stats.Backends = append(stats.Backends, BackendStats{
+			ID:          b.ID,
+			IPAddress:   b.IPAddress,
+			Port:        port,
+			ServiceName: b.Service,
+			Established: b.Established,
+			Pending:     b.Pending,
+			Status:      b.Status,
+		})
+
+		// sum up the service
+		if b.Service == "" {
+			continue
+		}
+
+		service, ok := services[b.Service]
+
+		if !ok {
+			service = &ServiceStats{ServiceName: b.Service}
+			services[b.Service] = service
+		}
+
+		service.Established += b.Established
+		service.Pending += b.Pending
+		service.Backends++
+
+		switch b.Status {
+		case BackendStatus_Up:
+			service.BackendsUp++
+		case BackendStatus_Down:
+			service.BackendsDown++
+		}
+	}
+
+	for _, s := range services {
+		stats.Services = append(stats.Services, *s)
+	}
+
+	sort.Slice(stats.Services, func(i, j int) bool {
+		return stats.Services[i].ServiceName < stats.Services[j].ServiceName
+	})
+
+	return stats, nil
+}
+
+// parseFlexibleInt parses a JSON value that is either a number or a string containing a number.
+func parseFlexibleInt(raw json.RawMessage) (int, error) {
+	value := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+
+	if value == "" || value == "null" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+//
+// System Statistics
+//
+
+type systemStatsResponse struct {
+	Description string          `json:"description"`
+	Params      systemStatsJSON `json:"params"`
+}
+
+type systemStatsJSON struct {
+	Hostname string                 `json:"hostname"`
+	Date     string                 `json:"date"`
+	CPU      CPUStats               `json:"cpu"`
+	Memory   MemoryStats            `json:"memory"`
+	Load     LoadStats              `json:"load"`
+	Network  map[string]interface{} `json:"network"`
+}
+
+// CPUStats contains the CPU usage in percent.
+type CPUStats struct {
+	Cores   int     `json:"cores"`
+	Idle    float64 `json:"idle"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	Nice    float64 `json:"nice"`
+	SoftIRQ float64 `json:"softirq"`
+	System  float64 `json:"sys"`
+	Total   float64 `json:"total"`
+	User    float64 `json:"user"`
+}
+
+// MemoryStats contains the memory usage in megabytes.
+type MemoryStats struct {
+	Buffers    float64 `json:"Buffers"`
+	Cached     float64 `json:"Cached"`
+	Free       float64 `json:"MemFree"`
+	Total      float64 `json:"MemTotal"`
+	Used       float64 `json:"MemUsed"`
+	SwapCached float64 `json:"SwapCached"`
+	SwapFree   float64 `json:"SwapFree"`
+	SwapTotal  float64 `json:"SwapTotal"`
+	SwapUsed   float64 `json:"SwapUsed"`
+}
+
+// LoadStats contains the system load averages.
+type LoadStats struct {
+	Last1Minute   float64 `json:"Last_1"`
+	Last5Minutes  float64 `json:"Last_5"`
+	Last15Minutes float64 `json:"Last_15"`
+}
+
+// NetworkThroughput contains the traffic of a network interface, as reported by the ZAPI.
+type NetworkThroughput struct {
+	Interface string
+	In        float64
+	Out       float64
+}
+
+// SystemStats contains the live resource usage of the loadbalancer.
+// See https://www.zevenet.com/zapidoc_ce_v3.1/#show-all-system-stats
+type SystemStats struct {
+	Hostname string
+	Date     string
+	CPU      CPUStats
+	Memory   MemoryStats
+	Load     LoadStats
+	Network  []NetworkThroughput
+}
+
+// String returns the system's hostname and load.
+func (ss *SystemStats) String() string {
+	return fmt.Sprintf("%v (CPU: %.1f%%, Load: %.2f)", ss.Hostname, ss.CPU.Total, ss.Load.Last1Minute)
+}
+
+// GetSystemStats returns the live resource usage of the loadbalancer.
+func (s *ZapiSession) GetSystemStats() (*SystemStats, error) {
+	return s.GetSystemStatsContext(context.Background())
+}
+
+// GetSystemStatsContext returns the live resource usage of the loadbalancer using the provided context.
+func (s *ZapiSession) GetSystemStatsContext(ctx context.Context) (*SystemStats, error) {
+	var result *systemStatsResponse
+
+	err := s.getForEntity(ctx, &result, "stats")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSystemStats(&result.Params)
+}
+
+func buildSystemStats(raw *systemStatsJSON) (*SystemStats, error) {
+	stats := &SystemStats{
+		Hostname: raw.Hostname,
+		Date:     raw.Date,
+		CPU:      raw.CPU,
+		Memory:   raw.Memory,
+		Load:     raw.Load,
+	}
+
+	// the network traffic is reported as "<interface> in" and "<interface> out"
+	network := make(map[string]*NetworkThroughput)
+
+	for key, value := range raw.Network {
+		idx := strings.LastIndex(key, " ")
+
+		if idx <= 0 {
+			continue
+		}
+
+		name := key[:idx]
+		amount, err := parseFlexibleFloat(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid network traffic of interface %v: %v", name, err)
+		}
+
+		nt, ok := network[name]
+
+		if !ok {
+			nt = &NetworkThroughput{Interface: name}
+			network[name] = nt
+		}
+
+		switch key[idx+1:] {
+		case "in":
+			nt.In = amount
+		case "out":
+			nt.Out = amount
+		}
+	}
+
+	for _, nt := range network {
+		stats.Network = append(stats.Network, *nt)
+	}
+
+	sort.Slice(stats.Network, func(i, j int) bool {
+		return stats.Network[i].Interface < stats.Network[j].Interface
+	})
+
+	return stats, nil
+}
+
+// parseFlexibleFloat converts a decoded JSON value that is either a number or a string containing a number.
+func parseFlexibleFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("Unknown number format: %v", value)
+}
@@ -0,0 +1,119 @@
+package zevenetlb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildFarmStats(t *testing.T) {
+	data := `{
+		"description": "List farm stats",
+		"backends": [
+			{"id": 0, "ip": "192.168.0.168", "port": 80, "service": "srv1", "established": 3, "pending": 1, "status": "up"},
+			{"id": 1, "ip": "192.168.0.169", "port": "80", "service": "srv1", "established": 2, "pending": 0, "status": "down"},
+			{"id": 0, "ip": "192.168.0.170", "port": "", "service": "srv2", "established": 1, "pending": 0, "status": "up"}
+		]
+	}`
+
+	var result farmStatsResponse
+
+	err := json.Unmarshal([]byte(data), &result)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := buildFarmStats("myfarm", result.Backends)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Established() != 6 || stats.Pending() != 1 {
+		t.Fatalf("Unexpected farm totals: %v", stats)
+	}
+
+	backend := stats.GetBackend("srv1", 1)
+
+	if backend == nil || backend.Port != 80 || backend.Status != BackendStatus_Down {
+		t.Fatalf("Unexpected backend: %v", backend)
+	}
+
+	service := stats.GetService("srv1")
+
+	if service == nil || service.Established != 5 || service.Backends != 2 || service.BackendsUp != 1 || service.BackendsDown != 1 {
+		t.Fatalf("Unexpected service: %v", service)
+	}
+}
+
+func TestBuildSystemStats(t *testing.T) {
+	data := `{
+		"description": "System stats",
+		"params": {
+			"hostname": "lb1",
+			"date": "Tue Mar 21 12:21:05 2017",
+			"cpu": {"cores": 2, "idle": 94.9, "total": 5.1, "user": 3.58, "sys": 1.52},
+			"load": {"Last_1": 0.02, "Last_5": 0.03, "Last_15": 0.01},
+			"memory": {"MemTotal": 2007.59, "MemUsed": 485.39, "MemFree": 1522.2},
+			"network": {"eth0 in": 72.43, "eth0 out": "85.13", "eth0.1 in": 1, "eth0.1 out": 2}
+		}
+	}`
+
+	var result systemStatsResponse
+
+	err := json.Unmarshal([]byte(data), &result)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := buildSystemStats(&result.Params)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.CPU.Cores != 2 || stats.Memory.Total != 2007.59 || stats.Load.Last5Minutes != 0.03 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	if len(stats.Network) != 2 || stats.Network[0].Interface != "eth0" || stats.Network[0].Out != 85.13 || stats.Network[1].In != 1 {
+		t.Fatalf("Unexpected network stats: %v", stats.Network)
+	}
+}
+
+func TestGetSystemStats(t *testing.T) {
+	session := createTestSession(t)
+
+	stats, err := session.GetSystemStats()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Stats: %v", stats)
+}
+
+func TestGetFarmStats(t *testing.T) {
+	session := createTestSession(t)
+
+	farms, err := session.GetAllFarms()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range farms {
+		stats, err := session.GetFarmStats(f.FarmName)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Logf("Farm: %v", stats)
+
+		for _, b := range stats.Backends {
+			t.Logf("  Backend: %v", b)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package zevenetlb
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -32,9 +33,14 @@ func (sv *SystemVersion) IsCommunityEdition() bool {
 
 // GetSystemVersion returns system version information.
 func (s *ZapiSession) GetSystemVersion() (*SystemVersion, error) {
+	return s.GetSystemVersionContext(context.Background())
+}
+
+// GetSystemVersionContext returns system version information using the provided context.
+func (s *ZapiSession) GetSystemVersionContext(ctx context.Context) (*SystemVersion, error) {
 	var result *systemVersionResponse
 
-	err := s.getForEntity(&result, "system", "version")
+	err := s.getForEntity(ctx, &result, "system", "version")
 
 	if err != nil {
 		return nil, err
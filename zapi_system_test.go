@@ -1,22 +1,59 @@
 package zevenetlb
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
 )
 
 func createTestSession(t *testing.T) *ZapiSession {
 	return createTestSessionEx(t, "")
 }
 
+// isLiveTest checks if the tests run against a real loadbalancer, instead of the fake one.
+func isLiveTest() bool {
+	return os.Getenv("ZAPI_KEY") != ""
+}
+
+// createFakeTestSession connects to a new fake loadbalancer, which is closed when the test ends.
+func createFakeTestSession(t *testing.T) *ZapiSession {
+	session, _ := createFakeTestSessionEx(t, nil)
+
+	return session
+}
+
+// createFakeTestSessionEx connects to a new fake loadbalancer using the options, and returns the server
+// to simulate changes on the loadbalancer, e.g. backend connections.
+func createFakeTestSessionEx(t *testing.T, options *ConfigOptions) (*ZapiSession, *zevenettest.Server) {
+	server := zevenettest.NewServer()
+	server.LoadSampleData()
+
+	t.Cleanup(server.Close)
+
+	session, err := Connect(server.URL, server.ZapiKey, options)
+
+	if err != nil {
+		t.Fatalf("Failed to connect to fake Zevenet API: %v", err)
+	}
+
+	return session, server
+}
+
 func createTestSessionEx(t *testing.T, apiKey string) *ZapiSession {
 	// retrieve api key if undefined
 	if apiKey == "" {
 		apiKey = os.Getenv("ZAPI_KEY")
 
+		// no loadbalancer available, use the fake one
 		if apiKey == "" {
-			t.Fatal("Failed to retrieve ZAPI key from environment variable ZAPI_KEY")
+			return createFakeTestSession(t)
 		}
 	}
 
@@ -30,8 +67,15 @@ func createTestSessionEx(t *testing.T, apiKey string) *ZapiSession {
 	// retrieve api version
 	zapiVersion := os.Getenv("ZAPI_VERSION")
 
+	// the test loadbalancer uses a self-signed certificate, pin it if possible
+	options := &ConfigOptions{
+		ZapiVersion:           zapiVersion,
+		TLSPinnedFingerprint:  os.Getenv("ZAPI_FINGERPRINT"),
+		TLSInsecureSkipVerify: os.Getenv("ZAPI_FINGERPRINT") == "",
+	}
+
 	// create the session
-	session, err := Connect(host, apiKey, &ConfigOptions{ZapiVersion: zapiVersion})
+	session, err := Connect(host, apiKey, options)
 
 	if err != nil {
 		t.Fatalf("Failed to connect to Zevenet API: %v", err)
@@ -85,3 +129,26 @@ func TestIsCommunityEdition(t *testing.T) {
 
 	t.Logf("Is Community Edition: %v", res.IsCommunityEdition())
 }
+
+func TestContextCancellation(t *testing.T) {
+	// simulate a loadbalancer which never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+
+	defer cancel()
+
+	_, err := ConnectContext(ctx, server.URL, "inval1dAp1K3y", nil)
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package zevenetlb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func createErrorTestSession(t *testing.T, statusCode int, contentType string, body string) (*ZapiSession, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/system/version") {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"description":"System version","params":{"appliance_version":"ZCE 5 (v5.0)"}}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+
+	session, err := Connect(server.URL, "zapi-key", nil)
+
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	return session, server.Close
+}
+
+func TestRequestErrorSentinels(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		sentinel   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+	}
+
+	for _, test := range tests {
+		session, closeServer := createErrorTestSession(t, test.statusCode, "application/json", `{"description":"Test","message":"Something went wrong"}`)
+
+		err := session.StartFarm("myfarm")
+
+		closeServer()
+
+		if !errors.Is(err, test.sentinel) {
+			t.Fatalf("Expected HTTP %v to match %v, but got: %v", test.statusCode, test.sentinel, err)
+		}
+
+		var reqErr RequestError
+
+		if !errors.As(err, &reqErr) {
+			t.Fatalf("Expected a RequestError, but got: %v", err)
+		}
+
+		if reqErr.StatusCode != test.statusCode || reqErr.Method != "PUT" || !strings.HasSuffix(reqErr.URL, "/farms/myfarm/actions") {
+			t.Fatalf("Unexpected request information: %v %v (%v)", reqErr.Method, reqErr.URL, reqErr.StatusCode)
+		}
+
+		if reqErr.Message != "Something went wrong" {
+			t.Fatalf("Unexpected message: %v", reqErr.Message)
+		}
+	}
+}
+
+func TestRequestErrorNonJSON(t *testing.T) {
+	session, closeServer := createErrorTestSession(t, http.StatusBadGateway, "text/html", "Bad Gateway")
+
+	defer closeServer()
+
+	_, err := session.GetAllFarms()
+
+	var reqErr RequestError
+
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected a RequestError, but got: %v", err)
+	}
+
+	if reqErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Unexpected status code: %v", reqErr.StatusCode)
+	}
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation) {
+		t.Fatalf("Unexpected sentinel match: %v", err)
+	}
+}
+
+func TestGetFarmNotFound(t *testing.T) {
+	session, closeServer := createErrorTestSession(t, http.StatusNotFound, "application/json", `{"description":"Farm","message":"The farmname myfarm does not exist."}`)
+
+	defer closeServer()
+
+	farm, err := session.GetFarm("myfarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm != nil {
+		t.Fatalf("Expected no farm, but got: %v", farm)
+	}
+}
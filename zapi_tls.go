@@ -0,0 +1,122 @@
+package zevenetlb
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CertificateError is returned if the loadbalancer's certificate is not trusted.
+type CertificateError struct {
+	Host string
+	Err  error
+}
+
+// Error returns the error message, including a hint on how to trust the certificate.
+func (e CertificateError) Error() string {
+	return fmt.Sprintf("the certificate of %v is not trusted, set TLSRootCAs or TLSPinnedFingerprint in the ConfigOptions: %v", e.Host, e.Err)
+}
+
+// Unwrap returns the underlying verification error.
+func (e CertificateError) Unwrap() error {
+	return e.Err
+}
+
+type fingerprintMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e fingerprintMismatchError) Error() string {
+	return fmt.Sprintf("certificate fingerprint %v does not match pinned fingerprint %v", e.Actual, e.Expected)
+}
+
+// isCertificateError checks if the error was caused by a failed certificate verification.
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var mismatch fingerprintMismatchError
+
+	return errors.As(err, &unknownAuthority) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &mismatch)
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the certificate, e.g. "AB:CD:...".
+// This is the format expected by *TLSPinnedFingerprint*.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	return formatFingerprint(sha256.Sum256(cert.Raw))
+}
+
+func formatFingerprint(sum [sha256.Size]byte) string {
+	parts := make([]string, len(sum))
+
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func parseFingerprint(fingerprint string) ([]byte, error) {
+	normalized := strings.NewReplacer(":", "", " ", "").Replace(fingerprint)
+
+	sum, err := hex.DecodeString(normalized)
+
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 fingerprint: %v", fingerprint)
+	}
+
+	return sum, nil
+}
+
+// tlsConfig builds the TLS configuration used to talk to the loadbalancer.
+func (opt *ConfigOptions) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		RootCAs:            opt.TLSRootCAs,
+		InsecureSkipVerify: opt.TLSInsecureSkipVerify,
+	}
+
+	if opt.TLSClientCertificate != nil {
+		config.Certificates = []tls.Certificate{*opt.TLSClientCertificate}
+	}
+
+	if opt.TLSPinnedFingerprint != "" && !opt.TLSInsecureSkipVerify {
+		expected, err := parseFingerprint(opt.TLSPinnedFingerprint)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the pinned fingerprint replaces the regular chain verification
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) <= 0 {
+				return errors.New("no certificate presented")
+			}
+
+			actual := sha256.Sum256(rawCerts[0])
+
+			if !bytes.Equal(actual[:], expected) {
+				var exp [sha256.Size]byte
+				copy(exp[:], expected)
+
+				return fingerprintMismatchError{
+					Expected: formatFingerprint(exp),
+					Actual:   formatFingerprint(actual),
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,93 @@
+package zevenetlb
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func createTestTLSServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"description":"System version","params":{"appliance_version":"ZCE 5 (v5.0)","zevenet_version":"5.0"}}`))
+	}))
+}
+
+func TestTLSUntrustedCertificate(t *testing.T) {
+	server := createTestTLSServer()
+
+	defer server.Close()
+
+	_, err := Connect(server.URL, "zapi-key", &ConfigOptions{})
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+
+	var certErr CertificateError
+
+	if !errors.As(err, &certErr) {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+}
+
+func TestTLSRootCAs(t *testing.T) {
+	server := createTestTLSServer()
+
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	_, err := Connect(server.URL, "zapi-key", &ConfigOptions{TLSRootCAs: pool})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSPinnedFingerprint(t *testing.T) {
+	server := createTestTLSServer()
+
+	defer server.Close()
+
+	fingerprint := CertificateFingerprint(server.Certificate())
+
+	_, err := Connect(server.URL, "zapi-key", &ConfigOptions{TLSPinnedFingerprint: strings.ToLower(fingerprint)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a different fingerprint must be rejected
+	_, err = Connect(server.URL, "zapi-key", &ConfigOptions{TLSPinnedFingerprint: strings.Repeat("00:", 31) + "00"})
+
+	var certErr CertificateError
+
+	if !errors.As(err, &certErr) {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+}
+
+func TestTLSInvalidFingerprint(t *testing.T) {
+	_, err := Connect("https://localhost", "zapi-key", &ConfigOptions{TLSPinnedFingerprint: "invalid"})
+
+	if err == nil || !strings.Contains(err.Error(), "invalid SHA-256 fingerprint") {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+}
+
+func TestTLSInsecureSkipVerify(t *testing.T) {
+	server := createTestTLSServer()
+
+	defer server.Close()
+
+	_, err := Connect(server.URL, "zapi-key", &ConfigOptions{TLSInsecureSkipVerify: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
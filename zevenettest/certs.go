@@ -0,0 +1,302 @@
+package zevenettest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultCertificateName is the certificate shipped with every loadbalancer.
+const defaultCertificateName = "zencert.pem"
+
+const certificateDateLayout = "Jan _2 15:04:05 2006 MST"
+
+// certificate is an uploaded certificate or a generated CSR.
+type certificate struct {
+	file       string
+	commonName string
+	issuer     string
+	created    time.Time
+	expires    time.Time
+	csr        bool
+	data       []byte
+}
+
+func (c *certificate) toJSON() map[string]interface{} {
+	res := map[string]interface{}{
+		"CN":       c.commonName,
+		"creation": c.created.UTC().Format(certificateDateLayout),
+		"file":     c.file,
+		"issuer":   c.issuer,
+	}
+
+	if c.csr {
+		res["expiration"] = ""
+		res["type"] = "CSR"
+	} else {
+		res["expiration"] = c.expires.UTC().Format(certificateDateLayout)
+		res["type"] = "Certificate"
+	}
+
+	return res
+}
+
+// newDefaultCertificate creates a self-signed certificate, similar to the one shipped with the loadbalancer.
+func newDefaultCertificate() *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	now := time.Now()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.zevenet.com", Organization: []string{"Zevenet"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		panic(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		panic(err)
+	}
+
+	var buffer bytes.Buffer
+
+	pem.Encode(&buffer, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	pem.Encode(&buffer, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return &certificate{
+		file:       defaultCertificateName,
+		commonName: template.Subject.CommonName,
+		issuer:     template.Subject.CommonName,
+		created:    template.NotBefore,
+		expires:    template.NotAfter,
+		data:       buffer.Bytes(),
+	}
+}
+
+func (s *Server) getCertificate(file string) *certificate {
+	for _, c := range s.certificates {
+		if c.file == file {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) handleCertificates(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 1 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, c := range s.certificates {
+			list = append(list, c.toJSON())
+		}
+
+		writeSuccess(w, "List certificates", map[string]interface{}{"params": list})
+	case len(p) == 1 && req.Method == http.MethodPost:
+		s.createCSR(w, req)
+	case len(p) == 2 && req.Method == http.MethodPost:
+		s.uploadCertificate(w, req, p[1])
+	case len(p) == 2 && req.Method == http.MethodGet:
+		c := s.getCertificate(p[1])
+
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Certificate %v not found.", p[1])
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-download")
+		w.WriteHeader(http.StatusOK)
+		w.Write(c.data)
+	case len(p) == 2 && req.Method == http.MethodDelete:
+		s.deleteCertificate(w, p[1])
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) uploadCertificate(w http.ResponseWriter, req *request, file string) {
+	if !strings.HasSuffix(file, ".pem") {
+		writeError(w, http.StatusBadRequest, "Invalid certificate name %v, the file has to end with .pem", file)
+		return
+	}
+
+	if s.getCertificate(file) != nil {
+		writeError(w, http.StatusBadRequest, "Certificate %v already exists.", file)
+		return
+	}
+
+	// find the leaf certificate
+	var leaf *x509.Certificate
+	var hasKey bool
+
+	data := req.Body
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+
+		if block == nil {
+			break
+		}
+
+		if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			hasKey = true
+			continue
+		}
+
+		if block.Type != "CERTIFICATE" || leaf != nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid certificate %v: %v", file, err)
+			return
+		}
+
+		leaf = cert
+	}
+
+	if leaf == nil || !hasKey {
+		writeError(w, http.StatusBadRequest, "The certificate %v requires a certificate and its private key.", file)
+		return
+	}
+
+	c := &certificate{
+		file:       file,
+		commonName: leaf.Subject.CommonName,
+		issuer:     leaf.Issuer.CommonName,
+		created:    leaf.NotBefore,
+		expires:    leaf.NotAfter,
+		data:       append([]byte(nil), req.Body...),
+	}
+
+	s.certificates = append(s.certificates, c)
+
+	writeSuccess(w, "Upload PEM certificate", map[string]interface{}{"message": "Certificate uploaded", "success": "true"})
+}
+
+func (s *Server) createCSR(w http.ResponseWriter, req *request) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	name := stringParam(body, "name")
+	fqdn := stringParam(body, "fqdn")
+
+	if name == "" || fqdn == "" {
+		writeError(w, http.StatusBadRequest, "The parameters name and fqdn are required.")
+		return
+	}
+
+	file := name + ".csr"
+
+	if s.getCertificate(file) != nil {
+		writeError(w, http.StatusBadRequest, "%v already exists.", file)
+		return
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate key: %v", err)
+		return
+	}
+
+	subject := pkix.Name{CommonName: fqdn}
+
+	if v := stringParam(body, "organization"); v != "" {
+		subject.Organization = []string{v}
+	}
+
+	if v := stringParam(body, "division"); v != "" {
+		subject.OrganizationalUnit = []string{v}
+	}
+
+	if v := stringParam(body, "locality"); v != "" {
+		subject.Locality = []string{v}
+	}
+
+	if v := stringParam(body, "state"); v != "" {
+		subject.Province = []string{v}
+	}
+
+	if v := stringParam(body, "country"); v != "" {
+		subject.Country = []string{v}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, key)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate CSR: %v", err)
+		return
+	}
+
+	s.certificates = append(s.certificates, &certificate{
+		file:       file,
+		commonName: fqdn,
+		issuer:     "NA",
+		created:    time.Now(),
+		csr:        true,
+		data:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+	})
+
+	writeSuccess(w, "Create CSR", map[string]interface{}{"message": "Certificate " + file + " created", "success": "true"})
+}
+
+func (s *Server) deleteCertificate(w http.ResponseWriter, file string) {
+	c := s.getCertificate(file)
+
+	if c == nil {
+		writeError(w, http.StatusNotFound, "Certificate %v not found.", file)
+		return
+	}
+
+	for _, f := range s.farms {
+		if contains(f.certificates, file) {
+			writeError(w, http.StatusBadRequest, "File can't be deleted because it's in use by the farm %v", f.name)
+			return
+		}
+	}
+
+	var certs []*certificate
+
+	for _, x := range s.certificates {
+		if x != c {
+			certs = append(certs, x)
+		}
+	}
+
+	s.certificates = certs
+
+	writeSuccess(w, "Delete certificate", map[string]interface{}{"message": "The Certificate " + file + " has been deleted.", "success": "true"})
+}
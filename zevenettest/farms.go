@@ -0,0 +1,839 @@
+package zevenettest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// farm is the state of a farm of any profile.
+type farm struct {
+	name           string
+	params         map[string]interface{}
+	services       []*service
+	backends       []*backend
+	certificates   []string
+	running        bool
+	restartPending bool
+}
+
+// service is the state of a service of an HTTP farm.
+type service struct {
+	params   map[string]interface{}
+	backends []*backend
+}
+
+// backend is the state of a backend of any farm profile.
+type backend struct {
+	params      map[string]interface{}
+	status      string
+	established int
+	pending     int
+}
+
+var farmReadOnlyParams = []string{"status", "certlist", "farmname", "services", "backends"}
+
+func newFarm(name string, profile string, virtualIP string, virtualPort int) *farm {
+	f := &farm{
+		name:    name,
+		running: true,
+	}
+
+	switch profile {
+	case "l4xnat":
+		f.params = map[string]interface{}{
+			"algorithm":   "weight",
+			"fgenabled":   "false",
+			"fglog":       "false",
+			"fgscript":    "",
+			"fgtimecheck": 5,
+			"listener":    "l4xnat",
+			"nattype":     "nat",
+			"persistence": "",
+			"protocol":    "all",
+			"ttl":         120,
+			"vip":         virtualIP,
+			"vport":       strconv.Itoa(virtualPort),
+		}
+	case "datalink":
+		f.params = map[string]interface{}{
+			"algorithm": "weight",
+			"listener":  "datalink",
+			"vip":       virtualIP,
+		}
+	default:
+		f.params = map[string]interface{}{
+			"cipherc":         "",
+			"ciphers":         "all",
+			"contimeout":      20,
+			"disable_sslv2":   "false",
+			"disable_sslv3":   "false",
+			"disable_tlsv1":   "false",
+			"disable_tlsv1_1": "false",
+			"disable_tlsv1_2": "false",
+			"error414":        "",
+			"error500":        "",
+			"error501":        "",
+			"error503":        "",
+			"httpverb":        "extendedHTTP",
+			"listener":        "http",
+			"reqtimeout":      30,
+			"restimeout":      45,
+			"resurrectime":    10,
+			"rewritelocation": "disabled",
+			"vip":             virtualIP,
+			"vport":           virtualPort,
+		}
+	}
+
+	return f
+}
+
+func (f *farm) profile() string {
+	return stringParam(f.params, "listener")
+}
+
+func (f *farm) isHTTP() bool {
+	profile := f.profile()
+
+	return profile == "http" || profile == "https"
+}
+
+// changed marks the farm as requiring a restart, which is only the case for HTTP farms.
+func (f *farm) changed() {
+	if f.isHTTP() && f.running {
+		f.restartPending = true
+	}
+}
+
+func (f *farm) allBackends() []*backend {
+	res := append([]*backend(nil), f.backends...)
+
+	for _, svc := range f.services {
+		res = append(res, svc.backends...)
+	}
+
+	return res
+}
+
+func (f *farm) status() string {
+	if !f.running {
+		return "down"
+	}
+
+	if f.restartPending {
+		return "needed restart"
+	}
+
+	var up, down, maintenance int
+
+	for _, b := range f.allBackends() {
+		switch b.status {
+		case "up":
+			up++
+		case "maintenance":
+			maintenance++
+		default:
+			down++
+		}
+	}
+
+	switch {
+	case up <= 0:
+		return "critical"
+	case down > 0:
+		return "problem"
+	case maintenance > 0:
+		return "maintenance"
+	}
+
+	return "up"
+}
+
+func (f *farm) getService(name string) *service {
+	for _, svc := range f.services {
+		if stringParam(svc.params, "id") == name {
+			return svc
+		}
+	}
+
+	return nil
+}
+
+func newService(name string) *service {
+	return &service{
+		params: map[string]interface{}{
+			"id":           name,
+			"fgenabled":    "false",
+			"fglog":        "false",
+			"fgscript":     "",
+			"fgtimecheck":  5,
+			"httpsb":       "false",
+			"leastresp":    "false",
+			"persistence":  "",
+			"sessionid":    "",
+			"ttl":          0,
+			"redirect":     "",
+			"redirecttype": "",
+			"urlp":         "",
+			"vhost":        "",
+		},
+	}
+}
+
+func newHTTPBackend(id int, ip string, port int) *backend {
+	return &backend{
+		params: map[string]interface{}{
+			"id":   id,
+			"ip":   ip,
+			"port": port,
+		},
+		status: "up",
+	}
+}
+
+func getBackend(backends []*backend, idStr string) *backend {
+	id, err := strconv.Atoi(idStr)
+
+	if err != nil {
+		return nil
+	}
+
+	for _, b := range backends {
+		if intParam(b.params, "id", -1) == id {
+			return b
+		}
+	}
+
+	return nil
+}
+
+func removeBackend(backends []*backend, b *backend) []*backend {
+	var res []*backend
+
+	for _, x := range backends {
+		if x != b {
+			res = append(res, x)
+		}
+	}
+
+	return res
+}
+
+// nextBackendID returns the lowest unused backend ID.
+func nextBackendID(backends []*backend) int {
+	id := 0
+
+	for _, b := range backends {
+		if bid := intParam(b.params, "id", -1); bid >= id {
+			id = bid + 1
+		}
+	}
+
+	return id
+}
+
+func (b *backend) toJSON() map[string]interface{} {
+	res := copyParams(b.params)
+	res["status"] = b.status
+
+	return res
+}
+
+func (s *Server) getFarm(name string) *farm {
+	for _, f := range s.farms {
+		if f.name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) handleFarms(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 1 && req.Method == http.MethodGet:
+		s.listFarms(w)
+		return
+	case len(p) == 1 && req.Method == http.MethodPost:
+		s.createFarm(w, req)
+		return
+	}
+
+	f := s.getFarm(p[1])
+
+	if f == nil {
+		writeError(w, http.StatusNotFound, "The farmname %v does not exist, not found.", p[1])
+		return
+	}
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		s.getFarmDetails(w, f)
+	case len(p) == 2 && req.Method == http.MethodPut:
+		s.updateFarm(w, req, f)
+	case len(p) == 2 && req.Method == http.MethodDelete:
+		s.deleteFarm(w, f)
+	case len(p) == 3 && p[2] == "actions" && req.Method == http.MethodPut:
+		s.farmAction(w, req, f)
+	case len(p) == 3 && p[2] == "fg" && req.Method == http.MethodPut:
+		s.updateFarmGuardian(w, req, f)
+	case len(p) >= 3 && p[2] == "certificates":
+		s.handleFarmCertificates(w, req, f)
+	case len(p) >= 3 && p[2] == "services":
+		s.handleServices(w, req, f)
+	case len(p) >= 3 && p[2] == "backends":
+		s.handleFarmBackends(w, req, f)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) listFarms(w http.ResponseWriter) {
+	list := []interface{}{}
+
+	for _, f := range s.farms {
+		list = append(list, map[string]interface{}{
+			"farmname": f.name,
+			"profile":  f.profile(),
+			"status":   f.status(),
+			"vip":      stringParam(f.params, "vip"),
+			"vport":    stringParam(f.params, "vport"),
+		})
+	}
+
+	writeSuccess(w, "List farms", map[string]interface{}{"params": list})
+}
+
+func (s *Server) createFarm(w http.ResponseWriter, req *request) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	name := stringParam(body, "farmname")
+	profile := stringParam(body, "profile")
+
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "The farm name is required.")
+		return
+	}
+
+	if s.getFarm(name) != nil {
+		writeError(w, http.StatusBadRequest, "Error trying to create a new farm, the farm name %v already exists.", name)
+		return
+	}
+
+	if profile != "http" && profile != "l4xnat" && profile != "datalink" {
+		writeError(w, http.StatusBadRequest, "The farm profile %v is not supported.", profile)
+		return
+	}
+
+	f := newFarm(name, profile, stringParam(body, "vip"), intParam(body, "vport", 0))
+
+	s.farms = append(s.farms, f)
+
+	sort.Slice(s.farms, func(i, j int) bool {
+		return s.farms[i].name < s.farms[j].name
+	})
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"description": "Creating farm '" + name + "'",
+		"params":      copyParams(body),
+	})
+}
+
+func (s *Server) getFarmDetails(w http.ResponseWriter, f *farm) {
+	params := copyParams(f.params)
+	params["status"] = f.status()
+
+	res := map[string]interface{}{
+		"params": params,
+	}
+
+	if f.isHTTP() {
+		certs := []interface{}{}
+
+		for i, c := range f.certificates {
+			certs = append(certs, map[string]interface{}{"file": c, "id": i + 1})
+		}
+
+		params["certlist"] = certs
+
+		services := []interface{}{}
+
+		for _, svc := range f.services {
+			sp := copyParams(svc.params)
+			backends := []interface{}{}
+
+			for _, b := range svc.backends {
+				backends = append(backends, b.toJSON())
+			}
+
+			sp["backends"] = backends
+			services = append(services, sp)
+		}
+
+		res["services"] = services
+	} else {
+		backends := []interface{}{}
+
+		for _, b := range f.backends {
+			backends = append(backends, b.toJSON())
+		}
+
+		res["backends"] = backends
+	}
+
+	writeSuccess(w, "List farm "+f.name, res)
+}
+
+func (s *Server) updateFarm(w http.ResponseWriter, req *request, f *farm) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	updateParams(f.params, body, farmReadOnlyParams)
+
+	// an HTTPS listener requires a certificate
+	if f.profile() == "https" && len(f.certificates) <= 0 {
+		f.certificates = append(f.certificates, defaultCertificateName)
+	}
+
+	f.changed()
+
+	writeSuccess(w, "Modify farm "+f.name, map[string]interface{}{"params": body})
+}
+
+func (s *Server) deleteFarm(w http.ResponseWriter, f *farm) {
+	var farms []*farm
+
+	for _, x := range s.farms {
+		if x != f {
+			farms = append(farms, x)
+		}
+	}
+
+	s.farms = farms
+
+	writeSuccess(w, "Delete farm "+f.name, map[string]interface{}{"message": "The Farm " + f.name + " has been deleted.", "success": "true"})
+}
+
+func (s *Server) farmAction(w http.ResponseWriter, req *request, f *farm) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	switch action := stringParam(body, "action"); action {
+	case "start", "restart":
+		f.running = true
+		f.restartPending = false
+	case "stop":
+		f.running = false
+		f.restartPending = false
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid action %v; the possible actions are stop, start and restart", action)
+		return
+	}
+
+	writeSuccess(w, "Set a new action in "+f.name, map[string]interface{}{"params": body})
+}
+
+var farmGuardianParams = []string{"fgenabled", "fglog", "fgscript", "fgtimecheck"}
+
+func (s *Server) updateFarmGuardian(w http.ResponseWriter, req *request, f *farm) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	params := f.params
+
+	if f.isHTTP() {
+		svc := f.getService(stringParam(body, "service"))
+
+		if svc == nil {
+			writeError(w, http.StatusBadRequest, "The service %v does not exist.", stringParam(body, "service"))
+			return
+		}
+
+		params = svc.params
+	} else if f.profile() != "l4xnat" {
+		writeError(w, http.StatusBadRequest, "Farm guardian is not supported by %v farms.", f.profile())
+		return
+	}
+
+	for _, k := range farmGuardianParams {
+		if v, ok := body[k]; ok {
+			params[k] = v
+		}
+	}
+
+	f.changed()
+
+	writeSuccess(w, "Modify farm guardian", map[string]interface{}{"params": body})
+}
+
+func (s *Server) handleFarmCertificates(w http.ResponseWriter, req *request, f *farm) {
+	p := req.Path
+
+	if f.profile() != "https" {
+		writeError(w, http.StatusBadRequest, "Certificates are only supported by HTTPS farms.")
+		return
+	}
+
+	switch {
+	case len(p) == 3 && req.Method == http.MethodPost:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		file := stringParam(body, "file")
+
+		if s.getCertificate(file) == nil {
+			writeError(w, http.StatusBadRequest, "The certificate %v does not exist.", file)
+			return
+		}
+
+		if contains(f.certificates, file) {
+			writeError(w, http.StatusBadRequest, "The certificate %v already exists in the farm %v.", file, f.name)
+			return
+		}
+
+		f.certificates = append(f.certificates, file)
+		f.changed()
+
+		writeSuccess(w, "Add certificate", map[string]interface{}{"success": "true"})
+	case len(p) == 4 && req.Method == http.MethodDelete:
+		if !contains(f.certificates, p[3]) {
+			writeError(w, http.StatusNotFound, "The certificate %v is not used by the farm %v, not found.", p[3], f.name)
+			return
+		}
+
+		if len(f.certificates) <= 1 {
+			writeError(w, http.StatusBadRequest, "The certificate %v can't be deleted, the farm %v requires at least one certificate.", p[3], f.name)
+			return
+		}
+
+		var certs []string
+
+		for _, c := range f.certificates {
+			if c != p[3] {
+				certs = append(certs, c)
+			}
+		}
+
+		f.certificates = certs
+		f.changed()
+
+		writeSuccess(w, "Delete farm certificate", map[string]interface{}{"success": "true"})
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleServices(w http.ResponseWriter, req *request, f *farm) {
+	p := req.Path
+
+	if !f.isHTTP() {
+		writeError(w, http.StatusBadRequest, "Services are only supported by HTTP farms.")
+		return
+	}
+
+	if len(p) == 3 && req.Method == http.MethodPost {
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		name := stringParam(body, "id")
+
+		if name == "" || f.getService(name) != nil {
+			writeError(w, http.StatusBadRequest, "Error creating the service %v, it already exists or the name is invalid.", name)
+			return
+		}
+
+		f.services = append(f.services, newService(name))
+		f.changed()
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "New service " + name, "params": body})
+		return
+	}
+
+	if len(p) < 4 {
+		writeNotFound(w, req)
+		return
+	}
+
+	svc := f.getService(p[3])
+
+	if svc == nil {
+		writeError(w, http.StatusNotFound, "The service %v does not exist, not found.", p[3])
+		return
+	}
+
+	switch {
+	case len(p) == 4 && req.Method == http.MethodPut:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		updateParams(svc.params, body, append([]string{"id", "farmname"}, farmGuardianParams...))
+		f.changed()
+
+		writeSuccess(w, "Modify service "+p[3], map[string]interface{}{"params": body})
+	case len(p) == 4 && req.Method == http.MethodDelete:
+		var services []*service
+
+		for _, x := range f.services {
+			if x != svc {
+				services = append(services, x)
+			}
+		}
+
+		f.services = services
+		f.changed()
+
+		writeSuccess(w, "Delete service", map[string]interface{}{"message": "The service " + p[3] + " has been deleted.", "success": "true"})
+	case len(p) >= 5 && p[4] == "backends":
+		s.handleServiceBackends(w, req, f, svc)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleServiceBackends(w http.ResponseWriter, req *request, f *farm, svc *service) {
+	p := req.Path
+
+	if len(p) == 5 && req.Method == http.MethodPost {
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		b := newHTTPBackend(nextBackendID(svc.backends), stringParam(body, "ip"), intParam(body, "port", 0))
+
+		updateParams(b.params, body, []string{"id"}, "timeout", "weight")
+
+		svc.backends = append(svc.backends, b)
+		f.changed()
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "New service backend", "params": b.toJSON()})
+		return
+	}
+
+	if len(p) < 6 {
+		writeNotFound(w, req)
+		return
+	}
+
+	b := getBackend(svc.backends, p[5])
+
+	if b == nil {
+		writeError(w, http.StatusNotFound, "Could not find the backend with ID %v, not found.", p[5])
+		return
+	}
+
+	switch {
+	case len(p) == 6 && req.Method == http.MethodPut:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		updateParams(b.params, body, []string{"id", "status", "farmname", "servicename"}, "timeout", "weight")
+		f.changed()
+
+		writeSuccess(w, "Modify service backend", map[string]interface{}{"params": body})
+	case len(p) == 6 && req.Method == http.MethodDelete:
+		svc.backends = removeBackend(svc.backends, b)
+		f.changed()
+
+		writeSuccess(w, "Delete service backend", map[string]interface{}{"message": "The backend with ID " + p[5] + " has been deleted.", "success": "true"})
+	case len(p) == 7 && p[6] == "maintenance" && req.Method == http.MethodPut:
+		s.backendMaintenance(w, req, b)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleFarmBackends(w http.ResponseWriter, req *request, f *farm) {
+	p := req.Path
+	profile := f.profile()
+
+	if profile != "l4xnat" && profile != "datalink" {
+		writeError(w, http.StatusBadRequest, "The backends of %v farms are managed by services.", profile)
+		return
+	}
+
+	if len(p) == 3 && req.Method == http.MethodPost {
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		b := &backend{
+			params: map[string]interface{}{
+				"id":       nextBackendID(f.backends),
+				"ip":       stringParam(body, "ip"),
+				"priority": intParam(body, "priority", 1),
+				"weight":   intParam(body, "weight", 1),
+			},
+			status: "up",
+		}
+
+		if profile == "l4xnat" {
+			b.params["port"] = stringParam(body, "port")
+		} else {
+			b.params["interface"] = stringParam(body, "interface")
+		}
+
+		f.backends = append(f.backends, b)
+		f.changed()
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "New farm backend", "params": b.toJSON()})
+		return
+	}
+
+	if len(p) < 4 {
+		writeNotFound(w, req)
+		return
+	}
+
+	b := getBackend(f.backends, p[3])
+
+	if b == nil {
+		writeError(w, http.StatusNotFound, "Could not find the backend with ID %v, not found.", p[3])
+		return
+	}
+
+	switch {
+	case len(p) == 4 && req.Method == http.MethodPut:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		updateParams(b.params, body, []string{"id", "status", "farmname"})
+		f.changed()
+
+		writeSuccess(w, "Modify backend", map[string]interface{}{"params": body})
+	case len(p) == 4 && req.Method == http.MethodDelete:
+		f.backends = removeBackend(f.backends, b)
+		f.changed()
+
+		writeSuccess(w, "Delete backend", map[string]interface{}{"message": "The backend with ID " + p[3] + " has been deleted.", "success": "true"})
+	case len(p) == 5 && p[4] == "maintenance" && req.Method == http.MethodPut:
+		s.backendMaintenance(w, req, b)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) backendMaintenance(w http.ResponseWriter, req *request, b *backend) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	switch action := stringParam(body, "action"); action {
+	case "maintenance":
+		b.status = "maintenance"
+
+		// cut existing connections
+		if stringParam(body, "mode") == "cut" {
+			b.established = 0
+			b.pending = 0
+		}
+	case "up":
+		b.status = "up"
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid action %v; the possible actions are up and maintenance", action)
+		return
+	}
+
+	writeSuccess(w, "Set backend status", map[string]interface{}{"params": body})
+}
+
+// findBackend returns a backend by farm, service and ID. The *serviceName* is empty for L4xNAT and DATALINK farms.
+func (s *Server) findBackend(farmName string, serviceName string, backendID int) (*backend, error) {
+	f := s.getFarm(farmName)
+
+	if f == nil {
+		return nil, fmt.Errorf("farm not found: %v", farmName)
+	}
+
+	backends := f.backends
+
+	if serviceName != "" {
+		svc := f.getService(serviceName)
+
+		if svc == nil {
+			return nil, fmt.Errorf("service not found: %v", serviceName)
+		}
+
+		backends = svc.backends
+	}
+
+	b := getBackend(backends, strconv.Itoa(backendID))
+
+	if b == nil {
+		return nil, fmt.Errorf("backend not found: %v", backendID)
+	}
+
+	return b, nil
+}
+
+// SetBackendConnections sets the number of connections reported by the statistics of a backend.
+// The *serviceName* is empty for L4xNAT and DATALINK farms.
+func (s *Server) SetBackendConnections(farmName string, serviceName string, backendID int, established int, pending int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.findBackend(farmName, serviceName, backendID)
+
+	if err != nil {
+		return err
+	}
+
+	b.established = established
+	b.pending = pending
+
+	return nil
+}
+
+// SetBackendStatus sets the status of a backend, e.g. "down" to simulate a failed farm guardian check.
+// The *serviceName* is empty for L4xNAT and DATALINK farms.
+func (s *Server) SetBackendStatus(farmName string, serviceName string, backendID int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.findBackend(farmName, serviceName, backendID)
+
+	if err != nil {
+		return err
+	}
+
+	b.status = status
+
+	return nil
+}
@@ -0,0 +1,653 @@
+package zevenettest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// newVirtualInterface creates a virtual interface on top of the NIC named by the prefix of *name*, e.g. "eth0:web".
+func (s *Server) newVirtualInterface(name string, ip string) map[string]interface{} {
+	vint := map[string]interface{}{
+		"name":    name,
+		"ip":      ip,
+		"parent":  name,
+		"netmask": "",
+		"gateway": "",
+		"mac":     "",
+		"status":  "up",
+	}
+
+	if idx := strings.Index(name, ":"); idx > 0 {
+		vint["parent"] = name[:idx]
+
+		if nic := s.getNetworkInterface(name[:idx]); nic != nil {
+			vint["netmask"] = nic["netmask"]
+			vint["gateway"] = nic["gateway"]
+			vint["mac"] = nic["mac"]
+		}
+	}
+
+	return vint
+}
+
+// newUnconfiguredNetworkInterface creates a NIC without an IP address, which can be used as a bonding slave.
+func newUnconfiguredNetworkInterface(name string, mac string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     name,
+		"ip":       "",
+		"netmask":  "",
+		"gateway":  "",
+		"mac":      mac,
+		"status":   "down",
+		"has_vlan": "false",
+	}
+}
+
+func (s *Server) getNetworkInterface(name string) map[string]interface{} {
+	for _, nic := range s.nics {
+		if nic["name"] == name {
+			return nic
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) getVLANInterface(name string) map[string]interface{} {
+	for _, vlan := range s.vlans {
+		if vlan["name"] == name {
+			return vlan
+		}
+	}
+
+	return nil
+}
+
+// updateHasVlan updates the "has_vlan" flag of the NICs.
+func (s *Server) updateHasVlan() {
+	for _, nic := range s.nics {
+		nic["has_vlan"] = "false"
+
+		for _, vlan := range s.vlans {
+			if vlan["parent"] == nic["name"] {
+				nic["has_vlan"] = "true"
+			}
+		}
+	}
+}
+
+func (s *Server) getBondInterface(name string) map[string]interface{} {
+	for _, bond := range s.bonds {
+		if bond["name"] == name {
+			return bond
+		}
+	}
+
+	return nil
+}
+
+// getBondOfSlave returns the bonding interface the NIC is a slave of, or *nil*.
+func (s *Server) getBondOfSlave(nicName string) map[string]interface{} {
+	for _, bond := range s.bonds {
+		for _, slave := range bond["slaves"].([]interface{}) {
+			if stringParam(slave.(map[string]interface{}), "name") == nicName {
+				return bond
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkBondSlave writes an error and returns false if the NIC cannot be added to a bonding interface.
+func (s *Server) checkBondSlave(w http.ResponseWriter, nicName string) bool {
+	nic := s.getNetworkInterface(nicName)
+
+	if nic == nil {
+		writeError(w, http.StatusBadRequest, "The NIC %v does not exist.", nicName)
+		return false
+	}
+
+	if stringParam(nic, "ip") != "" || nic["has_vlan"] == "true" {
+		writeError(w, http.StatusBadRequest, "The NIC %v is configured, it cannot be a slave.", nicName)
+		return false
+	}
+
+	if s.getBondOfSlave(nicName) != nil {
+		writeError(w, http.StatusBadRequest, "The NIC %v is already a slave.", nicName)
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) getVirtualInterface(name string) map[string]interface{} {
+	for _, vint := range s.virtualInterfaces {
+		if vint["name"] == name {
+			return vint
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) handleInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) >= 2 && p[1] == "nic":
+		s.handleNetworkInterfaces(w, req)
+	case len(p) >= 2 && p[1] == "vlan":
+		s.handleVLANInterfaces(w, req)
+	case len(p) >= 2 && p[1] == "bonding":
+		s.handleBondInterfaces(w, req)
+	case len(p) >= 2 && p[1] == "floating":
+		s.handleFloatingInterfaces(w, req)
+	case len(p) >= 2 && p[1] == "virtual":
+		s.handleVirtualInterfaces(w, req)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleNetworkInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, nic := range s.nics {
+			list = append(list, copyParams(nic))
+		}
+
+		writeSuccess(w, "List NIC interfaces", map[string]interface{}{"interfaces": list})
+	case len(p) >= 3:
+		nic := s.getNetworkInterface(p[2])
+
+		if nic == nil {
+			writeError(w, http.StatusNotFound, "The NIC %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		switch {
+		case len(p) == 3 && req.Method == http.MethodGet:
+			writeSuccess(w, "Show NIC "+p[2], map[string]interface{}{"interface": copyParams(nic)})
+		case len(p) == 3 && req.Method == http.MethodPut:
+			body, ok := req.decodeBody(w)
+
+			if !ok {
+				return
+			}
+
+			if s.getBondOfSlave(p[2]) != nil {
+				writeError(w, http.StatusBadRequest, "The NIC %v is a bonding slave, it cannot be configured.", p[2])
+				return
+			}
+
+			updateParams(nic, body, []string{"name", "mac", "status", "has_vlan"})
+
+			writeSuccess(w, "Configure NIC "+p[2], map[string]interface{}{"params": body})
+		case len(p) == 4 && p[3] == "actions" && req.Method == http.MethodPost:
+			s.handleInterfaceAction(w, req, nic)
+		default:
+			writeNotFound(w, req)
+		}
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleVirtualInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, vint := range s.virtualInterfaces {
+			list = append(list, copyParams(vint))
+		}
+
+		writeSuccess(w, "List virtual interfaces", map[string]interface{}{"interfaces": list})
+	case len(p) == 2 && req.Method == http.MethodPost:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		name := stringParam(body, "name")
+		ip := stringParam(body, "ip")
+		idx := strings.Index(name, ":")
+
+		if idx <= 0 || s.getNetworkInterface(name[:idx]) == nil {
+			writeError(w, http.StatusBadRequest, "The parent interface of %v does not exist.", name)
+			return
+		}
+
+		if s.getVirtualInterface(name) != nil {
+			writeError(w, http.StatusBadRequest, "The network interface %v already exists.", name)
+			return
+		}
+
+		if ip == "" {
+			writeError(w, http.StatusBadRequest, "The IP address is required.")
+			return
+		}
+
+		vint := s.newVirtualInterface(name, ip)
+
+		s.virtualInterfaces = append(s.virtualInterfaces, vint)
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "Add a virtual interface", "params": copyParams(vint)})
+	case len(p) == 3 && req.Method == http.MethodGet:
+		vint := s.getVirtualInterface(p[2])
+
+		if vint == nil {
+			writeError(w, http.StatusNotFound, "The virtual interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		writeSuccess(w, "Show virtual interface "+p[2], map[string]interface{}{"interface": copyParams(vint)})
+	case len(p) == 3 && req.Method == http.MethodDelete:
+		vint := s.getVirtualInterface(p[2])
+
+		if vint == nil {
+			writeError(w, http.StatusNotFound, "The virtual interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		var list []map[string]interface{}
+
+		for _, x := range s.virtualInterfaces {
+			if x["name"] != p[2] {
+				list = append(list, x)
+			}
+		}
+
+		s.virtualInterfaces = list
+
+		writeSuccess(w, "Delete virtual interface", map[string]interface{}{"message": "The virtual interface " + p[2] + " has been deleted.", "success": "true"})
+	case len(p) == 4 && p[3] == "actions" && req.Method == http.MethodPost:
+		vint := s.getVirtualInterface(p[2])
+
+		if vint == nil {
+			writeError(w, http.StatusNotFound, "The virtual interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		s.handleInterfaceAction(w, req, vint)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) handleVLANInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, vlan := range s.vlans {
+			list = append(list, copyParams(vlan))
+		}
+
+		writeSuccess(w, "List VLAN interfaces", map[string]interface{}{"interfaces": list})
+	case len(p) == 2 && req.Method == http.MethodPost:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		name := stringParam(body, "name")
+		idx := strings.LastIndex(name, ".")
+
+		if idx <= 0 || s.getNetworkInterface(name[:idx]) == nil {
+			writeError(w, http.StatusBadRequest, "The parent interface of %v does not exist.", name)
+			return
+		}
+
+		if tag, err := strconv.Atoi(name[idx+1:]); err != nil || tag < 1 || tag > 4094 {
+			writeError(w, http.StatusBadRequest, "The VLAN tag of %v is not valid.", name)
+			return
+		}
+
+		if s.getVLANInterface(name) != nil {
+			writeError(w, http.StatusBadRequest, "The network interface %v already exists.", name)
+			return
+		}
+
+		if stringParam(body, "ip") == "" || stringParam(body, "netmask") == "" {
+			writeError(w, http.StatusBadRequest, "The IP address and netmask are required.")
+			return
+		}
+
+		nic := s.getNetworkInterface(name[:idx])
+
+		vlan := map[string]interface{}{
+			"name":    name,
+			"parent":  name[:idx],
+			"ip":      stringParam(body, "ip"),
+			"netmask": stringParam(body, "netmask"),
+			"gateway": stringParam(body, "gateway"),
+			"mac":     nic["mac"],
+			"status":  "up",
+		}
+
+		s.vlans = append(s.vlans, vlan)
+		s.updateHasVlan()
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "Add a vlan interface", "params": copyParams(vlan)})
+	case len(p) >= 3:
+		vlan := s.getVLANInterface(p[2])
+
+		if vlan == nil {
+			writeError(w, http.StatusNotFound, "The VLAN interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		switch {
+		case len(p) == 3 && req.Method == http.MethodGet:
+			writeSuccess(w, "Show VLAN interface "+p[2], map[string]interface{}{"interface": copyParams(vlan)})
+		case len(p) == 3 && req.Method == http.MethodPut:
+			body, ok := req.decodeBody(w)
+
+			if !ok {
+				return
+			}
+
+			updateParams(vlan, body, []string{"name", "parent", "mac", "status"})
+
+			writeSuccess(w, "Modify VLAN interface "+p[2], map[string]interface{}{"params": body})
+		case len(p) == 3 && req.Method == http.MethodDelete:
+			var list []map[string]interface{}
+
+			for _, x := range s.vlans {
+				if x["name"] != p[2] {
+					list = append(list, x)
+				}
+			}
+
+			s.vlans = list
+			s.updateHasVlan()
+
+			writeSuccess(w, "Delete VLAN interface", map[string]interface{}{"message": "The VLAN interface " + p[2] + " has been deleted.", "success": "true"})
+		case len(p) == 4 && p[3] == "actions" && req.Method == http.MethodPost:
+			s.handleInterfaceAction(w, req, vlan)
+		default:
+			writeNotFound(w, req)
+		}
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+var bondModes = map[string]bool{
+	"balance-rr":    true,
+	"active-backup": true,
+	"balance-xor":   true,
+	"broadcast":     true,
+	"802.3ad":       true,
+	"balance-tlb":   true,
+	"balance-alb":   true,
+}
+
+func (s *Server) handleBondInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, bond := range s.bonds {
+			list = append(list, copyBond(bond))
+		}
+
+		writeSuccess(w, "List bonding interfaces", map[string]interface{}{"interfaces": list})
+	case len(p) == 2 && req.Method == http.MethodPost:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		name := stringParam(body, "name")
+		mode := stringParam(body, "mode")
+
+		if name == "" || s.getBondInterface(name) != nil || s.getNetworkInterface(name) != nil {
+			writeError(w, http.StatusBadRequest, "The network interface %v already exists.", name)
+			return
+		}
+
+		if !bondModes[mode] {
+			writeError(w, http.StatusBadRequest, "The bonding mode %v is not valid.", mode)
+			return
+		}
+
+		names, _ := body["slaves"].([]interface{})
+
+		if len(names) <= 0 {
+			writeError(w, http.StatusBadRequest, "At least one slave is required.")
+			return
+		}
+
+		var slaves []interface{}
+
+		seen := map[string]bool{}
+
+		for _, n := range names {
+			nicName, _ := n.(string)
+
+			if seen[nicName] {
+				writeError(w, http.StatusBadRequest, "The NIC %v is already a slave.", nicName)
+				return
+			}
+
+			if !s.checkBondSlave(w, nicName) {
+				return
+			}
+
+			seen[nicName] = true
+
+			slaves = append(slaves, map[string]interface{}{"name": nicName})
+		}
+
+		bond := map[string]interface{}{
+			"name":    name,
+			"mode":    mode,
+			"slaves":  slaves,
+			"ip":      "",
+			"netmask": "",
+			"gateway": "",
+			"mac":     s.getNetworkInterface(names[0].(string))["mac"],
+			"status":  "up",
+		}
+
+		s.bonds = append(s.bonds, bond)
+
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "Add a bond interface", "params": copyBond(bond)})
+	case len(p) >= 3:
+		bond := s.getBondInterface(p[2])
+
+		if bond == nil {
+			writeError(w, http.StatusNotFound, "The bonding interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		switch {
+		case len(p) == 3 && req.Method == http.MethodGet:
+			writeSuccess(w, "Show bonding interface "+p[2], map[string]interface{}{"interface": copyBond(bond)})
+		case len(p) == 3 && req.Method == http.MethodPut:
+			body, ok := req.decodeBody(w)
+
+			if !ok {
+				return
+			}
+
+			updateParams(bond, body, []string{"name", "mode", "slaves", "mac", "status"})
+
+			writeSuccess(w, "Modify bond interface "+p[2], map[string]interface{}{"params": body})
+		case len(p) == 3 && req.Method == http.MethodDelete:
+			var list []map[string]interface{}
+
+			for _, x := range s.bonds {
+				if x["name"] != p[2] {
+					list = append(list, x)
+				}
+			}
+
+			s.bonds = list
+
+			writeSuccess(w, "Delete bonding interface", map[string]interface{}{"message": "The bonding interface " + p[2] + " has been deleted.", "success": "true"})
+		case len(p) == 4 && p[3] == "slaves" && req.Method == http.MethodPost:
+			body, ok := req.decodeBody(w)
+
+			if !ok {
+				return
+			}
+
+			nicName := stringParam(body, "name")
+
+			if !s.checkBondSlave(w, nicName) {
+				return
+			}
+
+			bond["slaves"] = append(bond["slaves"].([]interface{}), map[string]interface{}{"name": nicName})
+
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"description": "Add a slave to a bond interface", "params": body})
+		case len(p) == 5 && p[3] == "slaves" && req.Method == http.MethodDelete:
+			slaves := []interface{}{}
+
+			for _, slave := range bond["slaves"].([]interface{}) {
+				if stringParam(slave.(map[string]interface{}), "name") != p[4] {
+					slaves = append(slaves, slave)
+				}
+			}
+
+			if len(slaves) == len(bond["slaves"].([]interface{})) {
+				writeError(w, http.StatusNotFound, "The NIC %v is not a slave of %v, not found.", p[4], p[2])
+				return
+			}
+
+			if len(slaves) <= 0 {
+				writeError(w, http.StatusBadRequest, "The last slave of %v cannot be removed.", p[2])
+				return
+			}
+
+			bond["slaves"] = slaves
+
+			writeSuccess(w, "Remove bonding slave interface", map[string]interface{}{"message": "The bonding slave interface " + p[4] + " has been removed.", "success": "true"})
+		case len(p) == 4 && p[3] == "actions" && req.Method == http.MethodPost:
+			s.handleInterfaceAction(w, req, bond)
+		default:
+			writeNotFound(w, req)
+		}
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+// copyBond copies a bonding interface, including its list of slaves.
+func copyBond(bond map[string]interface{}) map[string]interface{} {
+	c := copyParams(bond)
+
+	slaves := []interface{}{}
+
+	for _, slave := range bond["slaves"].([]interface{}) {
+		slaves = append(slaves, copyParams(slave.(map[string]interface{})))
+	}
+
+	c["slaves"] = slaves
+
+	return c
+}
+
+func (s *Server) handleFloatingInterfaces(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && req.Method == http.MethodGet:
+		list := []interface{}{}
+
+		for _, nic := range s.nics {
+			if ip, ok := s.floatingIPs[stringParam(nic, "name")]; ok {
+				list = append(list, map[string]interface{}{"interface": nic["name"], "floating_ip": ip})
+			}
+		}
+
+		writeSuccess(w, "List floating interfaces", map[string]interface{}{"params": list})
+	case len(p) == 3 && req.Method == http.MethodGet:
+		ip, ok := s.floatingIPs[p[2]]
+
+		if !ok {
+			writeError(w, http.StatusNotFound, "The floating interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		writeSuccess(w, "Show floating interface "+p[2], map[string]interface{}{"params": map[string]interface{}{"interface": p[2], "floating_ip": ip}})
+	case len(p) == 3 && req.Method == http.MethodPut:
+		body, ok := req.decodeBody(w)
+
+		if !ok {
+			return
+		}
+
+		if s.getNetworkInterface(p[2]) == nil {
+			writeError(w, http.StatusNotFound, "The NIC %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		ip := stringParam(body, "floating_ip")
+		found := false
+
+		for _, vint := range s.virtualInterfaces {
+			if vint["parent"] == p[2] && vint["ip"] == ip {
+				found = true
+			}
+		}
+
+		if !found {
+			writeError(w, http.StatusBadRequest, "The floating IP %v is not the address of a virtual interface on %v.", ip, p[2])
+			return
+		}
+
+		s.floatingIPs[p[2]] = ip
+
+		writeSuccess(w, "Modify floating interface", map[string]interface{}{"params": body})
+	case len(p) == 3 && req.Method == http.MethodDelete:
+		if _, ok := s.floatingIPs[p[2]]; !ok {
+			writeError(w, http.StatusNotFound, "The floating interface %v doesn't exist, not found.", p[2])
+			return
+		}
+
+		delete(s.floatingIPs, p[2])
+
+		writeSuccess(w, "Remove floating interface", map[string]interface{}{"message": "The floating interface " + p[2] + " has been removed.", "success": "true"})
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+// handleInterfaceAction brings an interface up or down.
+func (s *Server) handleInterfaceAction(w http.ResponseWriter, req *request, iface map[string]interface{}) {
+	body, ok := req.decodeBody(w)
+
+	if !ok {
+		return
+	}
+
+	action := stringParam(body, "action")
+
+	if action != "up" && action != "down" {
+		writeError(w, http.StatusBadRequest, "Invalid action %v; the possible actions are up and down", action)
+		return
+	}
+
+	iface["status"] = action
+
+	writeSuccess(w, "Action on interface "+stringParam(iface, "name"), map[string]interface{}{"params": body})
+}
@@ -0,0 +1,298 @@
+package zevenettest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RecorderMode is an enumeration of possible selections of the *Recorder* behavior.
+type RecorderMode int
+
+const (
+	// RecorderMode_Replay serves the responses from a previously recorded file, without any network access.
+	RecorderMode_Replay RecorderMode = iota
+
+	// RecorderMode_Record sends the requests to the loadbalancer and records the exchanges.
+	RecorderMode_Record
+)
+
+// redactedZapiKey replaces the ZAPI key in recorded exchanges.
+const redactedZapiKey = "REDACTED"
+
+// Cassette contains the recorded exchanges with a loadbalancer.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request sent to the loadbalancer. The host is not recorded, the *Path* includes the ZAPI version.
+type RecordedRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// RecordedResponse is the loadbalancer's response to a request.
+type RecordedResponse struct {
+	StatusCode int               `json:"status"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// recordedHeaders are the HTTP headers kept in the cassette.
+var recordedHeaders = []string{"Content-Type", "ZAPI_KEY"}
+
+// Recorder is a *http.RoundTripper* which records the exchanges with a loadbalancer to a file, or replays them.
+// The ZAPI key is redacted before saving.
+//
+//	recorder, err := zevenettest.NewRecorder("testdata/farms.json", zevenettest.RecorderMode_Replay)
+//
+//	session, err := zevenetlb.Connect(host, zapiKey, &zevenetlb.ConfigOptions{
+//		WrapTransport: recorder.Wrap,
+//	})
+//
+// When recording, call *Save()* when done.
+type Recorder struct {
+	mode     RecorderMode
+	filename string
+	next     http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	replayed []bool
+}
+
+// NewRecorder creates a new recorder. In replay mode, the recorded exchanges are loaded from *filename*.
+func NewRecorder(filename string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		mode:     mode,
+		filename: filename,
+		next:     http.DefaultTransport,
+	}
+
+	if mode != RecorderMode_Replay {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &r.cassette)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid cassette %v: %v", filename, err)
+	}
+
+	r.replayed = make([]bool, len(r.cassette.Interactions))
+
+	return r, nil
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() RecorderMode {
+	return r.mode
+}
+
+// Wrap sets the transport used for recording and returns the recorder.
+// It is meant to be used as *zevenetlb.ConfigOptions.WrapTransport*.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	if next != nil {
+		r.next = next
+	}
+
+	return r
+}
+
+// Cassette returns a copy of the exchanges recorded or loaded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the recorded exchanges to the file. In replay mode, it does nothing.
+func (r *Recorder) Save() error {
+	if r.mode == RecorderMode_Replay {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.filename, append(data, '\n'), 0644)
+}
+
+// RoundTrip records or replays a single exchange.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == RecorderMode_Replay {
+		return r.replay(req, recorded)
+	}
+
+	res, err := r.next.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// read the response, it is consumed twice
+	body, err := ioutil.ReadAll(res.Body)
+
+	res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	zapiKey := req.Header.Get("ZAPI_KEY")
+
+	interaction := Interaction{
+		Request: redactRequest(recorded, zapiKey),
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Headers:    recordHeaders(res.Header),
+			Body:       redact(string(body), zapiKey),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// replay returns the first recorded response matching the request, which has not been replayed yet.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	recorded = redactRequest(recorded, req.Header.Get("ZAPI_KEY"))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] {
+			continue
+		}
+
+		if interaction.Request.Method != recorded.Method || interaction.Request.Path != recorded.Path || interaction.Request.Body != recorded.Body {
+			continue
+		}
+
+		r.replayed[i] = true
+
+		res := &http.Response{
+			Status:        fmt.Sprintf("%d %v", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        make(http.Header),
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}
+
+		for k, v := range interaction.Response.Headers {
+			res.Header.Set(k, v)
+		}
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("No recorded response for %v %v in %v", recorded.Method, recorded.Path, r.filename)
+}
+
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Headers: recordHeaders(req.Header),
+	}
+
+	if req.Body == nil {
+		return recorded, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+
+	req.Body.Close()
+
+	if err != nil {
+		return recorded, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	recorded.Body = string(body)
+
+	return recorded, nil
+}
+
+func recordHeaders(header http.Header) map[string]string {
+	var res map[string]string
+
+	for _, k := range recordedHeaders {
+		if v := header.Get(k); v != "" {
+			if res == nil {
+				res = make(map[string]string)
+			}
+
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+func redactRequest(recorded RecordedRequest, zapiKey string) RecordedRequest {
+	recorded.Body = redact(recorded.Body, zapiKey)
+
+	if _, ok := recorded.Headers["ZAPI_KEY"]; ok {
+		headers := make(map[string]string, len(recorded.Headers))
+
+		for k, v := range recorded.Headers {
+			headers[k] = v
+		}
+
+		headers["ZAPI_KEY"] = redactedZapiKey
+		recorded.Headers = headers
+	}
+
+	return recorded
+}
+
+// redact removes any occurrence of the ZAPI key, e.g. in the response of the user settings.
+func redact(value string, zapiKey string) string {
+	if zapiKey == "" {
+		return value
+	}
+
+	return strings.Replace(value, zapiKey, redactedZapiKey, -1)
+}
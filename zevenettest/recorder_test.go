@@ -0,0 +1,113 @@
+package zevenettest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zevenettest")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "cassette.json")
+
+	// record the exchanges with the fake server
+	server := zevenettest.NewServer()
+	server.LoadSampleData()
+
+	defer server.Close()
+
+	recorder, err := zevenettest.NewRecorder(filename, zevenettest.RecorderMode_Record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := zevenetlb.Connect(server.URL, server.ZapiKey, &zevenetlb.ConfigOptions{
+		WrapTransport: recorder.Wrap,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = session.CreateFarmAsHTTP("recordedfarm", "10.209.0.31", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recordedFarms, err := session.GetAllFarms()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = recorder.Save()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the key must not be saved
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), server.ZapiKey) {
+		t.Fatal("The ZAPI key has not been redacted")
+	}
+
+	// replay without the server
+	server.Close()
+
+	replayer, err := zevenettest.NewRecorder(filename, zevenettest.RecorderMode_Replay)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err = zevenetlb.Connect(server.URL, "an0th3rK3y", &zevenetlb.ConfigOptions{
+		WrapTransport: replayer.Wrap,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = session.CreateFarmAsHTTP("recordedfarm", "10.209.0.31", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farms, err := session.GetAllFarms()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(farms) != len(recordedFarms) || farms[1].FarmName != "samplefarm" {
+		t.Fatalf("Unexpected farms: %v", farms)
+	}
+
+	// requests which have not been recorded fail
+	_, err = session.GetAllFarms()
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+}
@@ -0,0 +1,297 @@
+// Package zevenettest provides an in-process fake of the Zevenet ZAPI v3.1 for tests.
+//
+// The fake keeps its state in memory and implements the farm, service, backend, interface,
+// certificate, statistics and system endpoints used by the zevenetlb package:
+//
+//	server := zevenettest.NewServer()
+//	defer server.Close()
+//
+//	session, err := zevenetlb.Connect(server.URL, server.ZapiKey, nil)
+//
+// Its behavior follows the Community Edition 5.0, e.g. a newly created farm is running
+// and changes to HTTP farms require a restart.
+package zevenettest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// DefaultZapiKey is the ZAPI key accepted by a new server.
+const DefaultZapiKey = "zevenettest"
+
+// Server is a fake Zevenet loadbalancer.
+type Server struct {
+	// URL is the base URL of the server, to be passed to *zevenetlb.Connect()*.
+	URL string
+
+	// ZapiKey is the ZAPI key accepted by the server.
+	ZapiKey string
+
+	server *httptest.Server
+
+	mu                sync.Mutex
+	requests          []string
+	farms             []*farm
+	certificates      []*certificate
+	nics              []map[string]interface{}
+	vlans             []map[string]interface{}
+	bonds             []map[string]interface{}
+	floatingIPs       map[string]string
+	virtualInterfaces []map[string]interface{}
+	version           map[string]interface{}
+	clusterRole       string
+}
+
+// NewServer starts a new fake loadbalancer without any farms.
+// The server provides the default certificate "zencert.pem", the network interface "eth0",
+// and the unconfigured network interfaces "eth1" and "eth2".
+// Call *Close()* when done.
+func NewServer() *Server {
+	s := &Server{
+		ZapiKey:     DefaultZapiKey,
+		floatingIPs: map[string]string{},
+		version: map[string]interface{}{
+			"appliance_version": "ZCE 5 (v5.0)",
+			"hostname":          "zevenettest",
+			"kernel_version":    "3.16.0-4-amd64",
+			"system_date":       "Mon Jan  1 00:00:00 2018",
+			"zevenet_version":   "5.0",
+		},
+		nics: []map[string]interface{}{
+			{
+				"name":     "eth0",
+				"ip":       "10.209.0.10",
+				"netmask":  "255.255.255.0",
+				"gateway":  "10.209.0.1",
+				"mac":      "52:54:00:00:00:01",
+				"status":   "up",
+				"has_vlan": "false",
+			},
+			newUnconfiguredNetworkInterface("eth1", "52:54:00:00:00:02"),
+			newUnconfiguredNetworkInterface("eth2", "52:54:00:00:00:03"),
+		},
+	}
+
+	s.certificates = append(s.certificates, newDefaultCertificate())
+
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Requests returns all requests received so far, e.g. "PUT /farms/myfarm/actions".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// ResetRequests clears the list of received requests.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// LoadSampleData adds a running HTTP farm "samplefarm" on 10.209.0.30:80, including the virtual interface "eth0:sample",
+// the service "default" and a single backend.
+func (s *Server) LoadSampleData() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.virtualInterfaces = append(s.virtualInterfaces, s.newVirtualInterface("eth0:sample", "10.209.0.30"))
+
+	f := newFarm("samplefarm", "http", "10.209.0.30", 80)
+
+	svc := newService("default")
+	svc.backends = append(svc.backends, newHTTPBackend(0, "10.209.0.100", 80))
+
+	f.services = append(f.services, svc)
+
+	s.farms = append(s.farms, f)
+}
+
+// request is the parsed ZAPI request.
+type request struct {
+	Method string
+	Path   []string
+	Body   []byte
+	Header http.Header
+}
+
+// ServeHTTP handles a ZAPI request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	idx := strings.Index(r.URL.Path, "/zapi.cgi/")
+
+	if !strings.HasPrefix(r.URL.Path, "/zapi/") || idx < 0 {
+		writeError(w, http.StatusNotFound, "Unknown API path: %v", r.URL.Path)
+		return
+	}
+
+	if r.Header.Get("ZAPI_KEY") != s.ZapiKey {
+		writeError(w, http.StatusUnauthorized, "Authorization required")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path[idx+len("/zapi.cgi/"):], "/")
+
+	req := &request{
+		Method: r.Method,
+		Path:   strings.Split(path, "/"),
+		Header: r.Header,
+	}
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read request: %v", err)
+			return
+		}
+
+		req.Body = body
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, fmt.Sprintf("%v /%v", r.Method, path))
+
+	switch req.Path[0] {
+	case "farms":
+		s.handleFarms(w, req)
+	case "certificates":
+		s.handleCertificates(w, req)
+	case "interfaces":
+		s.handleInterfaces(w, req)
+	case "stats":
+		s.handleStats(w, req)
+	case "system":
+		s.handleSystem(w, req)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+// decodeBody parses the JSON request body.
+func (req *request) decodeBody(w http.ResponseWriter) (map[string]interface{}, bool) {
+	var body map[string]interface{}
+
+	err := json.Unmarshal(req.Body, &body)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON: %v", err)
+		return nil, false
+	}
+
+	return body, true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeSuccess(w http.ResponseWriter, description string, fields map[string]interface{}) {
+	res := map[string]interface{}{
+		"description": description,
+	}
+
+	for k, v := range fields {
+		res[k] = v
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, format string, args ...interface{}) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"description": "Zevenet test server",
+		"error":       "true",
+		"message":     fmt.Sprintf(format, args...),
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, req *request) {
+	writeError(w, http.StatusNotFound, "Request %v /%v not found.", req.Method, strings.Join(req.Path, "/"))
+}
+
+// copyParams creates a shallow copy of the parameters.
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(params))
+
+	for k, v := range params {
+		res[k] = v
+	}
+
+	return res
+}
+
+// updateParams copies all values of known (or explicitly allowed) parameters.
+func updateParams(params map[string]interface{}, body map[string]interface{}, readOnly []string, optional ...string) {
+	for k, v := range body {
+		if contains(readOnly, k) {
+			continue
+		}
+
+		if _, ok := params[k]; ok || contains(optional, k) {
+			params[k] = v
+		}
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stringParam returns a parameter as string.
+func stringParam(params map[string]interface{}, key string) string {
+	switch v := params[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// intParam returns a parameter as integer, or the default value.
+func intParam(params map[string]interface{}, key string, def int) int {
+	switch v := params[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		var res int
+
+		if _, err := fmt.Sscanf(v, "%d", &res); err == nil {
+			return res
+		}
+	}
+
+	return def
+}
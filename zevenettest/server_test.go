@@ -0,0 +1,209 @@
+package zevenettest_test
+
+import (
+	"errors"
+	"testing"
+
+	zevenetlb "github.com/konsorten/zevenet-lb-go"
+	"github.com/konsorten/zevenet-lb-go/zevenettest"
+)
+
+func createTestSession(t *testing.T) (*zevenetlb.ZapiSession, *zevenettest.Server) {
+	server := zevenettest.NewServer()
+
+	t.Cleanup(server.Close)
+
+	session, err := zevenetlb.Connect(server.URL, server.ZapiKey, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return session, server
+}
+
+func TestUnauthorized(t *testing.T) {
+	server := zevenettest.NewServer()
+
+	defer server.Close()
+
+	_, err := zevenetlb.Connect(server.URL, "inval1dAp1K3y", nil)
+
+	if !errors.Is(err, zevenetlb.ErrUnauthorized) {
+		t.Fatalf("Wrong error returned: %v", err)
+	}
+}
+
+func TestSampleData(t *testing.T) {
+	session, server := createTestSession(t)
+
+	server.LoadSampleData()
+
+	farm, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm == nil || farm.Status != zevenetlb.FarmStatus_Up || len(farm.Services) != 1 || len(farm.Services[0].Backends) != 1 {
+		t.Fatalf("Unexpected sample farm: %v", farm)
+	}
+
+	vint, err := session.GetVirtualInterface("eth0:sample")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vint == nil || vint.IP != farm.VirtualIP {
+		t.Fatalf("Unexpected sample interface: %v", vint)
+	}
+}
+
+func TestFarmStatus(t *testing.T) {
+	session, _ := createTestSession(t)
+
+	farm, err := session.CreateFarmAsHTTP("statusfarm", "10.209.0.31", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no backends
+	if farm.Status != zevenetlb.FarmStatus_Critical {
+		t.Fatalf("Expected status %v, but got %v", zevenetlb.FarmStatus_Critical, farm.Status)
+	}
+
+	_, err = session.CreateService(farm.FarmName, "default")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = session.CreateBackend(farm.FarmName, "default", "10.209.0.100", 80)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes require a restart
+	farm, err = session.GetFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.Status != zevenetlb.FarmStatus_NeedsRestart {
+		t.Fatalf("Expected status %v, but got %v", zevenetlb.FarmStatus_NeedsRestart, farm.Status)
+	}
+
+	err = session.RestartFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err = session.GetFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !farm.IsRunning() {
+		t.Fatalf("Expected the farm to run, but got %v", farm.Status)
+	}
+
+	err = session.StopFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err = session.GetFarm(farm.FarmName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if farm.Status != zevenetlb.FarmStatus_Down {
+		t.Fatalf("Expected status %v, but got %v", zevenetlb.FarmStatus_Down, farm.Status)
+	}
+}
+
+func TestBackendStats(t *testing.T) {
+	session, server := createTestSession(t)
+
+	server.LoadSampleData()
+
+	err := server.SetBackendConnections("samplefarm", "default", 0, 12, 3)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = server.SetBackendStatus("samplefarm", "default", 0, "down")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := session.GetFarmStats("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := stats.GetBackend("default", 0)
+
+	if backend == nil || backend.Established != 12 || backend.Pending != 3 || backend.Status != zevenetlb.BackendStatus_Down {
+		t.Fatalf("Unexpected backend stats: %v", backend)
+	}
+
+	// maintenance cuts the connections
+	farm, err := session.GetFarm("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = session.SetBackendMaintenance(&farm.Services[0].Backends[0], true, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = session.GetFarmStats("samplefarm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Established() != 0 {
+		t.Fatalf("Expected no connections, but got %v", stats)
+	}
+
+	// unknown backends are reported
+	err = server.SetBackendStatus("samplefarm", "default", 42, "up")
+
+	if err == nil {
+		t.Fatal("Error expected")
+	}
+}
+
+func TestRequests(t *testing.T) {
+	session, server := createTestSession(t)
+
+	server.ResetRequests()
+
+	_, err := session.GetAllFarms()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+
+	if len(requests) != 1 || requests[0] != "GET /farms" {
+		t.Fatalf("Unexpected requests: %v", requests)
+	}
+}
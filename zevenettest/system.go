@@ -0,0 +1,176 @@
+package zevenettest
+
+import (
+	"net/http"
+	"time"
+)
+
+func (s *Server) handleSystem(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 2 && p[1] == "version" && req.Method == http.MethodGet:
+		writeSuccess(w, "Get version", map[string]interface{}{"params": copyParams(s.version)})
+	case len(p) >= 2 && p[1] == "cluster" && req.Method == http.MethodGet:
+		s.handleCluster(w, req)
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+// SetClusterRole configures a cluster of two nodes, where the server is the node with the role "master", "backup", or "maintenance".
+// The remote node is the backup, unless the server is the master. An empty role removes the cluster.
+func (s *Server) SetClusterRole(role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clusterRole = role
+}
+
+func (s *Server) handleCluster(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	if s.clusterRole == "" {
+		writeError(w, http.StatusNotFound, "The cluster is not configured, not found.")
+		return
+	}
+
+	remoteRole := "master"
+
+	if s.clusterRole == "master" {
+		remoteRole = "backup"
+	}
+
+	local := map[string]interface{}{
+		"name":    "zevenettest",
+		"ip":      "10.209.0.10",
+		"role":    s.clusterRole,
+		"status":  "ok",
+		"message": clusterNodeMessage(s.clusterRole),
+	}
+
+	remote := map[string]interface{}{
+		"name":    "zevenettest2",
+		"ip":      "10.209.0.11",
+		"role":    remoteRole,
+		"status":  "ok",
+		"message": clusterNodeMessage(remoteRole),
+	}
+
+	switch {
+	case len(p) == 2:
+		writeSuccess(w, "Show the cluster configuration", map[string]interface{}{
+			"params": map[string]interface{}{
+				"check_interval": 3,
+				"failback":       "disabled",
+				"interface":      "eth0",
+				"nodes": []interface{}{
+					map[string]interface{}{"name": local["name"], "ip": local["ip"], "node": "local"},
+					map[string]interface{}{"name": remote["name"], "ip": remote["ip"], "node": "remote"},
+				},
+			},
+		})
+	case len(p) == 3 && p[2] == "nodes":
+		writeSuccess(w, "Cluster nodes status", map[string]interface{}{"params": []interface{}{local, remote}})
+	case len(p) == 4 && p[2] == "nodes" && p[3] == "localhost":
+		writeSuccess(w, "Cluster status for localhost", map[string]interface{}{"params": local})
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func clusterNodeMessage(role string) string {
+	if role == "master" {
+		return "Node online and active"
+	}
+
+	return "Node online and passive"
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, req *request) {
+	p := req.Path
+
+	switch {
+	case len(p) == 1 && req.Method == http.MethodGet:
+		s.systemStats(w)
+	case len(p) == 3 && p[1] == "farms" && req.Method == http.MethodGet:
+		s.farmStats(w, p[2])
+	default:
+		writeNotFound(w, req)
+	}
+}
+
+func (s *Server) systemStats(w http.ResponseWriter) {
+	network := map[string]interface{}{}
+
+	for _, nic := range s.nics {
+		name := stringParam(nic, "name")
+
+		network[name+" in"] = "0.00"
+		network[name+" out"] = "0.00"
+	}
+
+	writeSuccess(w, "System stats", map[string]interface{}{
+		"params": map[string]interface{}{
+			"hostname": s.version["hostname"],
+			"date":     time.Now().UTC().Format("Mon Jan _2 15:04:05 2006"),
+			"cpu": map[string]interface{}{
+				"cores": 2,
+				"idle":  99.0,
+				"total": 1.0,
+				"user":  1.0,
+			},
+			"memory": map[string]interface{}{
+				"MemTotal": 2048.0,
+				"MemFree":  1024.0,
+				"MemUsed":  1024.0,
+			},
+			"load": map[string]interface{}{
+				"Last_1":  0.0,
+				"Last_5":  0.0,
+				"Last_15": 0.0,
+			},
+			"network": network,
+		},
+	})
+}
+
+func (s *Server) farmStats(w http.ResponseWriter, farmName string) {
+	f := s.getFarm(farmName)
+
+	if f == nil {
+		writeError(w, http.StatusNotFound, "The farmname %v does not exist, not found.", farmName)
+		return
+	}
+
+	list := []interface{}{}
+
+	addBackend := func(b *backend, serviceName string) {
+		stats := map[string]interface{}{
+			"id":          b.params["id"],
+			"ip":          b.params["ip"],
+			"port":        b.params["port"],
+			"established": b.established,
+			"pending":     b.pending,
+			"status":      b.status,
+		}
+
+		if serviceName != "" {
+			stats["service"] = serviceName
+		}
+
+		list = append(list, stats)
+	}
+
+	for _, b := range f.backends {
+		addBackend(b, "")
+	}
+
+	for _, svc := range f.services {
+		for _, b := range svc.backends {
+			addBackend(b, stringParam(svc.params, "id"))
+		}
+	}
+
+	writeSuccess(w, "List farm stats", map[string]interface{}{"backends": list})
+}